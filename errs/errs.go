@@ -26,6 +26,12 @@ func (w wrapper) Error() string {
 	return strings.Join(toks, ": ")
 }
 
+// Unwrap returns the wrapped error, so that wrappers work with the standard
+// library's errors.Is and errors.As.
+func (w wrapper) Unwrap() error {
+	return w.underlying
+}
+
 func Wrap(err error, messages ...string) error {
 	if err == nil {
 		return nil
@@ -63,8 +69,10 @@ func Root(err error) error {
 		return nil
 	}
 
-	if w, ok := err.(wrapper); ok {
-		return Root(w.underlying)
+	if w, ok := err.(interface{ Unwrap() error }); ok {
+		if u := w.Unwrap(); u != nil {
+			return Root(u)
+		}
 	}
 
 	return err
@@ -2,6 +2,7 @@ package errs
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -34,4 +35,13 @@ func TestErrs(t *testing.T) {
 	if Root(w3) != e {
 		t.Errorf("Root(w3):\ngot:  %v\nwant: %v", Root(w3), e)
 	}
+
+	if !errors.Is(w3, e) {
+		t.Errorf("errors.Is(w3, e) should be true")
+	}
+
+	w4 := Wrap(fmt.Errorf("w4: %w", w3), "w5")
+	if Root(w4) != e {
+		t.Errorf("Root(w4):\ngot:  %v\nwant: %v", Root(w4), e)
+	}
 }
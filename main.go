@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"scgeme/scheme"
+)
+
+var exprFlag = flag.String("e", "", "evaluate `expression` after any files and print its value")
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-e expression] [file ...]\n\n", os.Args[0])
+	fmt.Fprintln(flag.CommandLine.Output(), "With no files or expression, starts a REPL if stdin is a terminal and")
+	fmt.Fprintln(flag.CommandLine.Output(), "otherwise runs stdin as a script. A file named - also reads stdin.")
+	fmt.Fprintln(flag.CommandLine.Output())
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	interp := scheme.NewInterpreter()
+
+	if flag.NArg() == 0 && *exprFlag == "" {
+		if isTerminal(os.Stdin) {
+			if err := repl(os.Stdin, os.Stdout, interp); err != nil {
+				fail(err)
+			}
+			return
+		}
+
+		if _, err := runReader("stdin", os.Stdin, interp); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	for _, path := range flag.Args() {
+		if _, err := runFile(path, interp); err != nil {
+			fail(err)
+		}
+	}
+
+	if *exprFlag != "" {
+		v, err := runSource("-e", *exprFlag, interp)
+		if err != nil {
+			fail(err)
+		}
+
+		fmt.Println(scheme.Repr(v))
+	}
+}
+
+func fail(err error) {
+	printError(os.Stderr, err)
+	os.Exit(1)
+}
+
+// printError writes err to w, followed by a backtrace of the Scheme procedure
+// calls in progress when it occurred, if any.
+func printError(w io.Writer, err error) {
+	fmt.Fprintln(w, "error:", err)
+
+	var located *scheme.Error
+	if errors.As(err, &located) && len(located.Stack()) > 0 {
+		fmt.Fprintln(w, "backtrace:")
+		fmt.Fprint(w, located.Backtrace())
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"scgeme/errs"
+	"scgeme/scheme"
+)
+
+const (
+	prompt             = "> "
+	continuationPrompt = "... "
+)
+
+// repl reads expressions from in and writes their values to out until in is
+// exhausted. Input that leaves an expression unclosed is buffered until a
+// later line closes it. All input is evaluated by the same interpreter, so
+// bindings persist from one input to the next.
+func repl(in io.Reader, out io.Writer, interp *scheme.Interpreter) error {
+	var (
+		scanner = bufio.NewScanner(in)
+		buf     string
+	)
+
+	fmt.Fprint(out, prompt)
+
+	for scanner.Scan() {
+		buf += scanner.Text() + "\n"
+
+		err := interp.EvalEach("", buf, func(v scheme.Value) {
+			fmt.Fprintln(out, scheme.Repr(v))
+		})
+		if errs.Root(err) == scheme.ErrUnclosedExpression {
+			fmt.Fprint(out, continuationPrompt)
+			continue
+		}
+
+		buf = ""
+
+		if err != nil {
+			printError(out, err)
+		}
+
+		fmt.Fprint(out, prompt)
+	}
+
+	fmt.Fprintln(out)
+
+	return scanner.Err()
+}
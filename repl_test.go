@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"scgeme/scheme"
+)
+
+func TestRepl(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   ``,
+			want: "> \n",
+		},
+		{
+			in:   "(+ 1 2)\n",
+			want: "> 3\n> \n",
+		},
+		{
+			in:   "\n",
+			want: "> > \n",
+		},
+		{
+			in:   "1 \"foo\"\n",
+			want: "> 1\n\"foo\"\n> \n",
+		},
+		{
+			in:   "(define a 1)\n(+ a 1)\n",
+			want: "> ()\n> 2\n> \n",
+		},
+		{
+			in:   "(+ 1\n2\n)\n",
+			want: "> ... ... 3\n> \n",
+		},
+		{
+			in:   "(list 1\n(+ 1 1)) (cons 3 4)\n",
+			want: "> ... (1 2)\n(3 . 4)\n> \n",
+		},
+		{
+			in:   ")\n1\n",
+			want: "> error: 1:1: invalid closing brace\n> 1\n> \n",
+		},
+		{
+			in:   "(car 1) 2\n3\n",
+			want: "> error: 1:1: (car 1): bad argument type\nbacktrace:\n  0: (car 1) at 1:1\n> 3\n> \n",
+		},
+		{
+			in:   "(+ 1\n",
+			want: "> ... \n",
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %q", i, c.in)
+
+		var out bytes.Buffer
+		if err := repl(strings.NewReader(c.in), &out, scheme.NewInterpreter()); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := out.String(); got != c.want {
+			t.Errorf("got:  %q\nwant: %q", got, c.want)
+		}
+	}
+}
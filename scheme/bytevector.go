@@ -0,0 +1,103 @@
+package scheme
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"scgeme/errs"
+)
+
+var errInvalidBytevector = errors.New("invalid bytevector literal")
+
+// bytevectorValue is a fixed-length, mutable sequence of bytes. Like vectors,
+// bytevectors are compared by identity.
+type bytevectorValue struct {
+	bytes []byte
+}
+
+func (_ *bytevectorValue) valueType() {
+	// does nothing
+}
+
+func (v *bytevectorValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case *bytevectorValue:
+		return v == other, nil
+	default:
+		return false, nil
+	}
+}
+
+// bytevectorToken opens a bytevector literal, #u8(bytes...). Since its
+// elements can only be numbers, the parser joins the whole literal into a
+// single token.
+const bytevectorToken = "#u8("
+
+// isBytevectorLiteral reports whether the token s is a bytevector literal,
+// valid or not.
+func isBytevectorLiteral(s string) bool {
+	return strings.HasPrefix(s, bytevectorToken)
+}
+
+// bytevectorLiteral returns the token for the bytevector literal parsed as
+// expr, whose elements must all be tokens.
+func bytevectorLiteral(expr *compoundExpression) (*tokenExpression, error) {
+	parts := make([]string, len(expr.children))
+	for i, c := range expr.children {
+		t, ok := c.(*tokenExpression)
+		if !ok || isDot(t) || t.token[0] == '"' {
+			return nil, errs.Wrap(errInvalidBytevector, c.location().String())
+		}
+		parts[i] = t.token
+	}
+
+	return &tokenExpression{
+		token: bytevectorToken + strings.Join(parts, " ") + ")",
+		span:  expr.span,
+	}, nil
+}
+
+// parseBytevector parses a bytevector literal token, whose elements must be
+// exact integers between 0 and 255.
+func parseBytevector(s string) ([]byte, bool) {
+	if !isBytevectorLiteral(s) || !strings.HasSuffix(s, ")") {
+		return nil, false
+	}
+
+	fields := strings.Fields(s[len(bytevectorToken) : len(s)-1])
+
+	res := make([]byte, len(fields))
+	for i, f := range fields {
+		n, ok := parseNumber(f, 10)
+		if !ok {
+			return nil, false
+		}
+
+		b, ok := byteOf(n)
+		if !ok {
+			return nil, false
+		}
+		res[i] = b
+	}
+
+	return res, true
+}
+
+// byteOf returns v as a byte, if it is an exact integer in range.
+func byteOf(v value) (byte, bool) {
+	n, ok := v.(numberValue)
+	if !ok || n.underlying < 0 || n.underlying > 255 {
+		return 0, false
+	}
+	return byte(n.underlying), true
+}
+
+// reprBytevector returns the representation of b as a bytevector literal.
+func reprBytevector(b []byte) string {
+	parts := make([]string, len(b))
+	for i, c := range b {
+		parts[i] = strconv.Itoa(int(c))
+	}
+	return bytevectorToken + strings.Join(parts, " ") + ")"
+}
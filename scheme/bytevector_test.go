@@ -0,0 +1,116 @@
+package scheme
+
+import (
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestBytevectors(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{src: `#u8(1 2 255)`, want: `#u8(1 2 255)`},
+		{src: `#u8()`, want: `#u8()`},
+		{src: `#u8(#xff #b10 #;3 4)`, want: `#u8(255 2 4)`},
+		{src: `'(a #u8(1) #(#u8(2)))`, want: `(a #u8(1) #(#u8(2)))`},
+		{src: "`(1 ,#u8(2) #u8(3))", want: `(1 #u8(2) #u8(3))`},
+		{src: `(list (bytevector? #u8(1)) (bytevector? #(1)) (bytevector? "a"))`, want: `(#t #f #f)`},
+		{src: `(list (make-bytevector 2 7) (make-bytevector 3) (bytevector) (bytevector 1 2))`, want: `(#u8(7 7) #u8(0 0 0) #u8() #u8(1 2))`},
+		{src: `(list (bytevector-length #u8()) (bytevector-length #u8(1 2 3)))`, want: `(0 3)`},
+		{src: `(list (bytevector-u8-ref #u8(5 6 7) 0) (bytevector-u8-ref #u8(5 6 7) 2))`, want: `(5 7)`},
+		{src: `(let ((b (make-bytevector 3 0))) (bytevector-u8-set! b 1 200) b)`, want: `#u8(0 200 0)`},
+		{src: `(list (bytevector-copy #u8(1 2 3)) (bytevector-copy #u8(1 2 3) 1) (bytevector-copy #u8(1 2 3) 1 2))`, want: `(#u8(1 2 3) #u8(2 3) #u8(2))`},
+		{src: `(let* ((a #u8(1 2)) (b (bytevector-copy a))) (bytevector-u8-set! b 0 9) (list a b))`, want: `(#u8(1 2) #u8(9 2))`},
+		{src: `(list (bytevector-append #u8(1) #u8() #u8(2 3)) (bytevector-append))`, want: `(#u8(1 2 3) #u8())`},
+		{src: `(string->utf8 "aé€")`, want: `#u8(97 195 169 226 130 172)`},
+		{src: `(string->utf8 "aé€" 1 2)`, want: `#u8(195 169)`},
+		{src: `(list (utf8->string #u8(97 195 169)) (utf8->string #u8(97 98 99) 1) (utf8->string #u8(97 255 98)))`, want: `("aé" "bc" "a�b")`},
+		{src: `(utf8->string (string->utf8 "λx"))`, want: `"λx"`},
+		{src: `(list (equal? #u8(1 2) (bytevector 1 2)) (equal? #u8(1) #u8(2)) (eqv? #u8() #u8()) (let ((b #u8(1))) (eq? b b)))`, want: `(#t #f #f #t)`},
+		{
+			src: `
+				(define b (make-bytevector 8 0))
+				(bytevector-u16-set! b 0 #x1234 'little)
+				(bytevector-u16-set! b 2 #x1234 'big)
+				(bytevector-s32-set! b 4 -2 'big)
+				b`,
+			want: `#u8(52 18 18 52 255 255 255 254)`,
+		},
+		{
+			src: `
+				(define b #u8(1 2 3 4 255 255 255 255))
+				(list
+				  (bytevector-u16-ref b 0 'little)
+				  (bytevector-u16-ref b 0 'big)
+				  (bytevector-s16-ref b 6 'big)
+				  (bytevector-u32-ref b 4 'little)
+				  (bytevector-s32-ref b 4 'little)
+				  (bytevector-u32-ref b 0 'big))`,
+			want: `(513 258 -1 4294967295 -1 16909060)`,
+		},
+		{
+			src: `
+				(define b (make-bytevector 8))
+				(bytevector-u64-set! b 0 18446744073709551615 'big)
+				(list (bytevector-u64-ref b 0 'big) (bytevector-s64-ref b 0 'little))`,
+			want: `(18446744073709551615 -1)`,
+		},
+		{
+			src: `
+				(define b (make-bytevector 8))
+				(bytevector-s64-set! b 0 -9223372036854775808 'little)
+				(list b (bytevector-s64-ref b 0 'little))`,
+			want: `(#u8(0 0 0 0 0 0 0 128) -9223372036854775808)`,
+		},
+		{src: `(let ((h (make-hash-table))) (hash-table-set! h #u8(1 2) 'x) (hash-table-ref h (bytevector 1 2)))`, want: `x`},
+		{
+			src: `
+				(define-syntax bytes
+				  (syntax-rules ()
+				    ((_ b) (list b (bytevector-length b)))))
+				(bytes #u8(1 2))`,
+			want: `(#u8(1 2) 2)`,
+		},
+		{src: `(bytevector-u8-ref #u8(1 2) 2)`, wantErr: errIndexOutOfRange},
+		{src: `(bytevector-u8-ref #u8() 0)`, wantErr: errIndexOutOfRange},
+		{src: `(bytevector-copy #u8(1 2) 2 1)`, wantErr: errIndexOutOfRange},
+		{src: `(bytevector-u32-ref #u8(1 2 3) 0 'big)`, wantErr: errIndexOutOfRange},
+		{src: `(bytevector-u16-set! (make-bytevector 4) 3 0 'big)`, wantErr: errIndexOutOfRange},
+		{src: `(make-bytevector 100000000 0)`, wantErr: errIndexOutOfRange},
+		{src: `(bytevector-u8-set! (make-bytevector 1) 0 256)`, wantErr: errInvalidArgumentType},
+		{src: `(bytevector 1 -1)`, wantErr: errInvalidArgumentType},
+		{src: `(make-bytevector 1 1.0)`, wantErr: errInvalidArgumentType},
+		{src: `(bytevector-u8-ref #(1) 0)`, wantErr: errInvalidArgumentType},
+		{src: `(bytevector-append #u8(1) '(2))`, wantErr: errInvalidArgumentType},
+		{src: `(utf8->string "a")`, wantErr: errInvalidArgumentType},
+		{src: `(bytevector-u16-ref #u8(1 2) 0 'middle)`, wantErr: errInvalidArgumentType},
+		{src: `(bytevector-u16-set! (make-bytevector 2) 0 65536 'big)`, wantErr: errInvalidArgumentType},
+		{src: `(bytevector-s16-set! (make-bytevector 2) 0 32768 'big)`, wantErr: errInvalidArgumentType},
+		{src: `(bytevector-u32-set! (make-bytevector 4) 0 -1 'big)`, wantErr: errInvalidArgumentType},
+		{src: `(bytevector-length)`, wantErr: errWrongNumberOfArguments},
+		{src: `#u8(1 256)`, wantErr: errInvalidBytevector},
+		{src: `#u8(a)`, wantErr: errInvalidBytevector},
+		{src: `#u8((1))`, wantErr: errInvalidBytevector},
+		{src: `#u8("a")`, wantErr: errInvalidBytevector},
+		{src: `#u8(1 . 2)`, wantErr: errInvalidBytevector},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		in := NewInterpreter()
+		got, gotErr := in.Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
+		}
+	}
+}
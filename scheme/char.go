@@ -0,0 +1,105 @@
+package scheme
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var errInvalidCharacter = errors.New("invalid character literal")
+
+type charValue struct {
+	underlying rune
+}
+
+func (_ charValue) valueType() {
+	// does nothing
+}
+
+func (v charValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case charValue:
+		return v.underlying == other.underlying, nil
+	default:
+		return false, nil
+	}
+}
+
+func (v charValue) greaterThan(other value) (bool, error) {
+	switch other := other.(type) {
+	case charValue:
+		return v.underlying > other.underlying, nil
+	default:
+		return false, errIncomparableValueTypes
+	}
+}
+
+// charNames are the names of characters that may be written #\name.
+var charNames = map[string]rune{
+	"alarm":     '\a',
+	"backspace": '\b',
+	"delete":    0x7f,
+	"escape":    0x1b,
+	"newline":   '\n',
+	"null":      0,
+	"return":    '\r',
+	"space":     ' ',
+	"tab":       '\t',
+}
+
+// isCharLiteral reports whether the token s is a character literal, valid or
+// not.
+func isCharLiteral(s string) bool {
+	return strings.HasPrefix(s, `#\`)
+}
+
+// parseChar parses a character literal: #\ followed by the character itself,
+// its name, or x and its code point in hexadecimal.
+func parseChar(s string) (rune, bool) {
+	if !isCharLiteral(s) || len(s) == 2 {
+		return 0, false
+	}
+
+	s = s[2:]
+
+	if r, size := utf8.DecodeRuneInString(s); size == len(s) {
+		return r, r != utf8.RuneError
+	}
+
+	if r, ok := charNames[s]; ok {
+		return r, true
+	}
+
+	if s[0] == 'x' || s[0] == 'X' {
+		n, err := strconv.ParseUint(s[1:], 16, 32)
+		if err != nil || !validRune(int(n)) {
+			return 0, false
+		}
+		return rune(n), true
+	}
+
+	return 0, false
+}
+
+// validRune reports whether n is the code point of a Unicode scalar value,
+// which excludes the surrogates.
+func validRune(n int) bool {
+	return n >= 0 && n <= unicode.MaxRune && (n < 0xd800 || n > 0xdfff)
+}
+
+// reprChar returns the representation of r as a character literal.
+func reprChar(r rune) string {
+	for name, c := range charNames {
+		if c == r {
+			return `#\` + name
+		}
+	}
+
+	if !unicode.IsGraphic(r) {
+		return `#\x` + strconv.FormatInt(int64(r), 16)
+	}
+
+	return `#\` + string(r)
+}
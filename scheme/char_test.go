@@ -0,0 +1,95 @@
+package scheme
+
+import (
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestParseChar(t *testing.T) {
+	cases := []struct {
+		src    string
+		want   rune
+		wantOk bool
+	}{
+		{src: `#\a`, want: 'a', wantOk: true},
+		{src: `#\A`, want: 'A', wantOk: true},
+		{src: `#\(`, want: '(', wantOk: true},
+		{src: `#\ `, want: ' ', wantOk: true},
+		{src: `#\λ`, want: 'λ', wantOk: true},
+		{src: `#\x`, want: 'x', wantOk: true},
+		{src: `#\space`, want: ' ', wantOk: true},
+		{src: `#\newline`, want: '\n', wantOk: true},
+		{src: `#\tab`, want: '\t', wantOk: true},
+		{src: `#\null`, want: 0, wantOk: true},
+		{src: `#\x41`, want: 'A', wantOk: true},
+		{src: `#\x3bb`, want: 'λ', wantOk: true},
+		{src: `#\X41`, want: 'A', wantOk: true},
+		{src: `#\`},
+		{src: `#\ab`},
+		{src: `#\Space`},
+		{src: `#\xd800`},
+		{src: `#\x110000`},
+		{src: `#\xg`},
+		{src: `a`},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %s", i, c.src)
+
+		got, ok := parseChar(c.src)
+		if ok != c.wantOk {
+			t.Errorf("ok:\ngot:  %v\nwant: %v", ok, c.wantOk)
+			continue
+		}
+
+		if ok && got != c.want {
+			t.Errorf("value:\ngot:  %q\nwant: %q", got, c.want)
+		}
+	}
+}
+
+func TestChars(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{src: `#\a`, want: `#\a`},
+		{src: `'(#\a #\space #\()`, want: `(#\a #\space #\()`},
+		{src: `(list (char? #\a) (char? "a") (char? 97))`, want: `(#t #f #f)`},
+		{src: `(list (char->integer #\A) (char->integer #\x3bb) (integer->char 97))`, want: `(65 955 #\a)`},
+		{src: `(list (char-upcase #\a) (char-downcase #\A) (char-foldcase #\Λ) (char-upcase #\1))`, want: `(#\A #\a #\λ #\1)`},
+		{src: `(list (char-alphabetic? #\a) (char-alphabetic? #\1) (char-numeric? #\1) (char-whitespace? #\tab))`, want: `(#t #f #t #t)`},
+		{src: `(list (char-upper-case? #\A) (char-upper-case? #\a) (char-lower-case? #\a))`, want: `(#t #f #t)`},
+		{src: `(list (digit-value #\7) (digit-value #\x665) (digit-value #\a))`, want: `(7 5 #f)`},
+		{src: `(list (char=? #\a #\a) (char=? #\a #\b) (char<? #\a #\b #\c) (char<? #\a #\c #\b))`, want: `(#t #f #t #f)`},
+		{src: `(list (char>? #\b #\a) (char<=? #\a #\a) (char>=? #\a #\b))`, want: `(#t #t #f)`},
+		{src: `(list (char-ci=? #\a #\A) (char-ci<? #\a #\B) (char<? #\a #\B))`, want: `(#t #t #f)`},
+		{src: `(list (eqv? #\a #\a) (equal? '(#\a) (list #\a)) (eqv? #\a "a"))`, want: `(#t #t #f)`},
+		{src: `(< #\a #\b)`, want: `#t`},
+		{src: `#\bogus`, wantErr: errInvalidCharacter},
+		{src: `(char->integer "a")`, wantErr: errInvalidArgumentType},
+		{src: `(integer->char -1)`, wantErr: errInvalidArgumentType},
+		{src: `(integer->char #xd800)`, wantErr: errInvalidArgumentType},
+		{src: `(char<? #\a 1)`, wantErr: errInvalidArgumentType},
+		{src: `(char=? #\a)`, wantErr: errWrongNumberOfArguments},
+	}
+
+	in := NewInterpreter()
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := in.Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package scheme
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"scgeme/errs"
+)
+
+type expressionType int
+
+var (
+	errInvalidExpressionType     = errors.New("invalid expression type")
+	errInvalidCompoundExpression = errors.New("invalid compound expression")
+	errUnquoteOutsideQuasiquote  = errors.New("unquote outside of quasiquote")
+	errInvalidClause             = errors.New("invalid clause")
+)
+
+const (
+	exprInvalid expressionType = iota
+
+	exprNull = iota
+
+	// token expression types
+	exprNumber      = iota
+	exprBoolean     = iota
+	exprString      = iota
+	exprChar        = iota
+	exprVector      = iota
+	exprBytevector  = iota
+	exprDereference = iota
+
+	// compound expression types
+	exprDefine       = iota
+	exprBegin        = iota
+	exprIf           = iota
+	exprLambda       = iota
+	exprLet          = iota
+	exprPrimitive    = iota
+	exprQuote        = iota
+	exprQuasiquote   = iota
+	exprDefineSyntax = iota
+	exprLetSyntax    = iota
+	exprLetrecSyntax = iota
+	exprDefineMacro  = iota
+	exprCond         = iota
+	exprCase         = iota
+	exprWhen         = iota
+	exprUnless       = iota
+	exprAnd          = iota
+	exprOr           = iota
+	exprLetStar      = iota
+	exprLetrec       = iota
+	exprLetrecStar   = iota
+	exprSet          = iota
+	exprApplication  = iota
+)
+
+func classify(expr expression) (expressionType, error) {
+	switch e := expr.(type) {
+	case *tokenExpression:
+		return classifyToken(e)
+	case *compoundExpression:
+		return classifyCompound(e)
+	default:
+		return exprInvalid, errInvalidExpressionType
+	}
+}
+
+func classifyToken(expr *tokenExpression) (expressionType, error) {
+	switch {
+	case expr.token == "null":
+		return exprNull, nil
+	case expr.token == "#t":
+		return exprBoolean, nil
+	case expr.token == "#f":
+		return exprBoolean, nil
+	case isBytevectorLiteral(expr.token):
+		if _, ok := parseBytevector(expr.token); !ok {
+			return exprInvalid, errs.Wrap(errInvalidBytevector, expr.token)
+		}
+		return exprBytevector, nil
+	case isNumberLiteral(expr.token):
+		return exprNumber, nil
+	case expr.token[0] == '"':
+		return exprString, nil
+	case isCharLiteral(expr.token):
+		if _, ok := parseChar(expr.token); !ok {
+			return exprInvalid, errs.Wrap(errInvalidCharacter, expr.token)
+		}
+		return exprChar, nil
+	default:
+		return exprDereference, nil
+	}
+}
+
+// isNumberLiteral reports whether s is a numeric literal, checking first, for
+// speed, that it could be one: numbers begin with a digit, sign, point or
+// prefix, and those without a prefix contain a decimal digit. With a prefix,
+// the digits may be hexadecimal ones, as in #xff.
+func isNumberLiteral(s string) bool {
+	switch c := s[0]; {
+	case c == '#':
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		if !strings.ContainsAny(s, "0123456789") {
+			return false
+		}
+	default:
+		return false
+	}
+
+	_, ok := parseNumber(s, 10)
+	return ok
+}
+
+func classifyCompound(expr *compoundExpression) (expressionType, error) {
+	if expr.vector {
+		return exprVector, nil
+	}
+
+	if len(expr.children) == 0 {
+		return exprNull, nil
+	}
+
+	if isCompoundExpression(expr.children[0]) {
+		return exprApplication, nil
+	}
+
+	c, ok := expr.children[0].(*tokenExpression)
+	if !ok {
+		return exprInvalid, errInvalidCompoundExpression
+	}
+
+	switch c.name() {
+	case "define":
+		if !validDefinition(expr) {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprDefine, nil
+	case "define-macro":
+		if !validDefinition(expr) {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprDefineMacro, nil
+	case "set!":
+		if len(expr.children) != 3 || !isTokenExpression(expr.children[1]) {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprSet, nil
+	case "begin":
+		return exprBegin, nil
+	case "if":
+		if n := len(expr.children); n != 3 && n != 4 {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprIf, nil
+	case "cond":
+		if len(expr.children) < 2 {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		if err := checkClauses("cond", expr.children[1:]); err != nil {
+			return exprInvalid, err
+		}
+		return exprCond, nil
+	case "case":
+		if len(expr.children) < 3 {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		if err := checkClauses("case", expr.children[2:]); err != nil {
+			return exprInvalid, err
+		}
+		return exprCase, nil
+	case "when":
+		if len(expr.children) < 3 {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprWhen, nil
+	case "unless":
+		if len(expr.children) < 3 {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprUnless, nil
+	case "and":
+		return exprAnd, nil
+	case "or":
+		return exprOr, nil
+	case "lambda":
+		if len(expr.children) < 3 || !validFormals(expr.children[1]) {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprLambda, nil
+	case "let":
+		bindings := 1
+		if len(expr.children) > 1 && isTokenExpression(expr.children[1]) { // named let
+			if !isIdentifier(expr.children[1]) {
+				return exprInvalid, errInvalidCompoundExpression
+			}
+			bindings = 2
+		}
+
+		if len(expr.children) < bindings+2 || !validBindings(expr.children[bindings]) {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprLet, nil
+	case "let*":
+		if len(expr.children) < 3 || !validBindings(expr.children[1]) {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprLetStar, nil
+	case "letrec":
+		if len(expr.children) < 3 || !validBindings(expr.children[1]) {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprLetrec, nil
+	case "letrec*":
+		if len(expr.children) < 3 || !validBindings(expr.children[1]) {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprLetrecStar, nil
+	case "define-syntax":
+		if len(expr.children) != 3 || !isTokenExpression(expr.children[1]) {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprDefineSyntax, nil
+	case "let-syntax":
+		if len(expr.children) < 3 || !validBindings(expr.children[1]) {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprLetSyntax, nil
+	case "letrec-syntax":
+		if len(expr.children) < 3 || !validBindings(expr.children[1]) {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprLetrecSyntax, nil
+	case "quote":
+		if len(expr.children) != 2 {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprQuote, nil
+	case "quasiquote":
+		if len(expr.children) != 2 {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprQuasiquote, nil
+	case "unquote", "unquote-splicing":
+		return exprInvalid, errUnquoteOutsideQuasiquote
+	case "primitive":
+		if len(expr.children) < 2 {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		if _, ok := expr.children[1].(*tokenExpression); !ok {
+			return exprInvalid, errInvalidCompoundExpression
+		}
+		return exprPrimitive, nil
+	default:
+		return exprApplication, nil
+	}
+}
+
+// checkClauses checks the clauses of a cond or case expression, returning an
+// error located at the first malformed clause. A clause is (test body...) in a
+// cond, or ((datum...) body...) in a case, where the body may be omitted in a
+// cond, or be "=> receiver". The test of the last clause may be else.
+func checkClauses(form string, clauses []expression) error {
+	for i, clause := range clauses {
+		c, ok := clause.(*compoundExpression)
+		if !ok || len(c.children) == 0 {
+			return invalidClause(form, clause)
+		}
+
+		switch {
+		case isKeyword(c.children[0], "else"):
+			if i != len(clauses)-1 || len(c.children) < 2 {
+				return invalidClause(form, clause)
+			}
+		case form == "case":
+			if !isCompoundExpression(c.children[0]) || len(c.children) < 2 {
+				return invalidClause(form, clause)
+			}
+		}
+
+		if len(c.children) > 1 && isKeyword(c.children[1], "=>") && len(c.children) != 3 {
+			return invalidClause(form, clause)
+		}
+	}
+
+	return nil
+}
+
+func invalidClause(form string, clause expression) error {
+	return locate(errs.Wrap(errInvalidClause, form), clause)
+}
+
+// isKeyword reports whether expr is the identifier name, which may have been
+// renamed by macro expansion.
+func isKeyword(expr expression, name string) bool {
+	t, ok := expr.(*tokenExpression)
+	return ok && t.name() == name
+}
+
+// validDefinition reports whether expr has the shape of a define expression,
+// either (define name expression) or (define (name formals...) body...).
+func validDefinition(expr *compoundExpression) bool {
+	if len(expr.children) < 3 {
+		return false
+	}
+
+	switch v := expr.children[1].(type) {
+	case *tokenExpression: // standard variable binding
+		return len(expr.children) == 3
+	case *compoundExpression: // function declaration shorthand
+		if len(v.children) == 0 {
+			return false
+		}
+
+		for _, p := range v.children {
+			if !isTokenExpression(p) {
+				return false
+			}
+		}
+		return true
+	default:
+		panic(fmt.Sprintf("invalid expression: %v", v))
+	}
+}
+
+// validFormals reports whether expr is a list of formal parameters, as in a
+// lambda expression.
+func validFormals(expr expression) bool {
+	formals, ok := expr.(*compoundExpression)
+	if !ok {
+		return false
+	}
+
+	for _, p := range formals.children {
+		if !isTokenExpression(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIdentifier reports whether expr is a token that names a variable, rather
+// than a literal.
+func isIdentifier(expr expression) bool {
+	t, ok := expr.(*tokenExpression)
+	if !ok || isDot(t) {
+		return false
+	}
+
+	k, err := classifyToken(t)
+	return err == nil && k == exprDereference
+}
+
+// validBindings reports whether expr is a list of (identifier expression)
+// bindings, as in a let expression.
+func validBindings(expr expression) bool {
+	assignments, ok := expr.(*compoundExpression)
+	if !ok {
+		return false
+	}
+
+	for _, c := range assignments.children {
+		assign, ok := c.(*compoundExpression)
+		if !ok || len(assign.children) != 2 || !isTokenExpression(assign.children[0]) {
+			return false
+		}
+	}
+
+	return true
+}
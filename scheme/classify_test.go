@@ -0,0 +1,456 @@
+package scheme
+
+import (
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    expressionType
+		wantErr error
+	}{
+		{
+			src:  `null`,
+			want: exprNull,
+		},
+		{
+			src:  `()`,
+			want: exprNull,
+		},
+		{
+			src:  `100`,
+			want: exprNumber,
+		},
+		{
+			src:  `-100`,
+			want: exprNumber,
+		},
+		{
+			src:  `#t`,
+			want: exprBoolean,
+		},
+		{
+			src:  `#f`,
+			want: exprBoolean,
+		},
+		{
+			src:  `"foo bar"`,
+			want: exprString,
+		},
+		{
+			src:  `foo`,
+			want: exprDereference,
+		},
+		{
+			src:  `(define a b)`,
+			want: exprDefine,
+		},
+		{
+			src:  `(define a (b))`,
+			want: exprDefine,
+		},
+		{
+			src:  `(define (a) b)`,
+			want: exprDefine,
+		},
+		{
+			src:  `(define (a) (b))`,
+			want: exprDefine,
+		},
+		{
+			src:  `(define (a b) c)`,
+			want: exprDefine,
+		},
+		{
+			src:  `(define (a b c) d)`,
+			want: exprDefine,
+		},
+		{
+			src:  `(define (a b) c d)`,
+			want: exprDefine,
+		},
+		{
+			src:     `(define)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(define a)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(define a b c)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(define () a)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(define ((a)) b)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(define (a (b)) c)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(set! a b)`,
+			want: exprSet,
+		},
+		{
+			src:     `(set! a)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(set! (a) b)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(begin)`,
+			want: exprBegin,
+		},
+		{
+			src:  `(begin a b (c))`,
+			want: exprBegin,
+		},
+		{
+			src:  `(if a b c)`,
+			want: exprIf,
+		},
+		{
+			src:  `(if a b (c))`,
+			want: exprIf,
+		},
+		{
+			src:  `(if a b c)`,
+			want: exprIf,
+		},
+		{
+			src:     `(if)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(if a)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(if a b)`,
+			want: exprIf,
+		},
+		{
+			src:     `(if a b c d)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(cond (a b) (c) (d => e) (else f))`,
+			want: exprCond,
+		},
+		{
+			src:     `(cond)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(cond a)`,
+			wantErr: errInvalidClause,
+		},
+		{
+			src:     `(cond ())`,
+			wantErr: errInvalidClause,
+		},
+		{
+			src:     `(cond (else a) (b c))`,
+			wantErr: errInvalidClause,
+		},
+		{
+			src:     `(cond (else))`,
+			wantErr: errInvalidClause,
+		},
+		{
+			src:     `(cond (a =>))`,
+			wantErr: errInvalidClause,
+		},
+		{
+			src:     `(cond (a => b c))`,
+			wantErr: errInvalidClause,
+		},
+		{
+			src:  `(case a ((1 2) b) ((c) => d) (else e))`,
+			want: exprCase,
+		},
+		{
+			src:     `(case a)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(case a (1 b))`,
+			wantErr: errInvalidClause,
+		},
+		{
+			src:     `(case a ((1)))`,
+			wantErr: errInvalidClause,
+		},
+		{
+			src:  `(when a b c)`,
+			want: exprWhen,
+		},
+		{
+			src:     `(when a)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(unless a b c)`,
+			want: exprUnless,
+		},
+		{
+			src:     `(unless a)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(and)`,
+			want: exprAnd,
+		},
+		{
+			src:  `(or a b c)`,
+			want: exprOr,
+		},
+		{
+			src:  `(lambda () c)`,
+			want: exprLambda,
+		},
+		{
+			src:  `(lambda (a b) c)`,
+			want: exprLambda,
+		},
+		{
+			src:  `(lambda (a) (c))`,
+			want: exprLambda,
+		},
+		{
+			src:  `(lambda (a) b c d)`,
+			want: exprLambda,
+		},
+		{
+			src:     `(lambda)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(lambda (a))`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(lambda a b)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(lambda ((a)) 1)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(lambda (a (b c)) 1)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(let () a)`,
+			want: exprLet,
+		},
+		{
+			src:  `(let ((a b)) c)`,
+			want: exprLet,
+		},
+		{
+			src:  `(let ((a b) (c d)) e)`,
+			want: exprLet,
+		},
+		{
+			src:  `(let ((a b)) c d e)`,
+			want: exprLet,
+		},
+		{
+			src:  `(let a ((b c)) d)`,
+			want: exprLet,
+		},
+		{
+			src:  `(let a () d)`,
+			want: exprLet,
+		},
+		{
+			src:     `(let a ((b c)))`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(let a b c)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(let 5 () 1)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(let "a" () 1)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(let* ((a b) (c a)) c)`,
+			want: exprLetStar,
+		},
+		{
+			src:     `(let* (a) b)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(letrec ((a b)) c)`,
+			want: exprLetrec,
+		},
+		{
+			src:     `(letrec ((a b)))`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(letrec* ((a b)) c)`,
+			want: exprLetrecStar,
+		},
+		{
+			src:     `(letrec* a b)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(let)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(let a)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(let (a) b)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(let (()) a)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(let ((a)) b)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(primitive +)`,
+			want: exprPrimitive,
+		},
+		{
+			src:     `(primitive)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(primitive (x) 1)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(quote a)`,
+			want: exprQuote,
+		},
+		{
+			src:  `'(a b)`,
+			want: exprQuote,
+		},
+		{
+			src:     `(quote)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(quote a b)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  "`(a ,b)",
+			want: exprQuasiquote,
+		},
+		{
+			src:     `(quasiquote a b)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `,a`,
+			wantErr: errUnquoteOutsideQuasiquote,
+		},
+		{
+			src:     `,@a`,
+			wantErr: errUnquoteOutsideQuasiquote,
+		},
+		{
+			src:  `(define-syntax a (syntax-rules ()))`,
+			want: exprDefineSyntax,
+		},
+		{
+			src:     `(define-syntax (a) b)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(define-macro (a . b) c)`,
+			want: exprDefineMacro,
+		},
+		{
+			src:  `(define-macro a b)`,
+			want: exprDefineMacro,
+		},
+		{
+			src:     `(define-macro a)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(let-syntax ((a b)) c)`,
+			want: exprLetSyntax,
+		},
+		{
+			src:  `(letrec-syntax ((a b)) c)`,
+			want: exprLetrecSyntax,
+		},
+		{
+			src:     `(let-syntax (a) c)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(a)`,
+			want: exprApplication,
+		},
+		{
+			src:  `(a b c)`,
+			want: exprApplication,
+		},
+		{
+			src:  `(())`,
+			want: exprApplication,
+		},
+		{
+			src:  `((a) b)`,
+			want: exprApplication,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %s", i, c.src)
+
+		exprs, err := parseSource("", c.src)
+		if err != nil {
+			t.Errorf("%s: parse error: %v", c.src, err)
+			continue
+		}
+
+		if len(exprs) != 1 {
+			t.Errorf("%s: invalid parse result; should have one root expression: %v", c.src, exprs)
+		}
+
+		got, gotErr := classify(exprs[0])
+
+		if got != c.want {
+			t.Errorf("got:  %v\nwant: %v", got, c.want)
+		}
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package scheme
+
+import (
+	"errors"
+
+	"scgeme/errs"
+)
+
+var (
+	errInvalidMacroTransformer = errors.New("macro transformer must be a procedure")
+	errInvalidMacroResult      = errors.New("macro expansion is not a valid expression")
+)
+
+// procMacroValue is a macro defined with define-macro. Its transformer is an
+// ordinary procedure, which is applied to the unevaluated operands of a use of
+// the macro, as data, and returns the expansion as data. Expansion is not
+// hygienic.
+type procMacroValue struct {
+	name        string
+	transformer value
+}
+
+func (_ *procMacroValue) valueType() {
+	// does nothing
+}
+
+func (v *procMacroValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case *procMacroValue:
+		return v == other, nil
+	default:
+		return false, nil
+	}
+}
+
+func (m *procMacroValue) expand(expr expression, _ *frame) (expression, error) {
+	c := mustExpressionChildren(expr)
+
+	args := make([]value, len(c)-1)
+	for i, operand := range c[1:] {
+		v, err := datum(operand)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+
+	v, err := apply(m.transformer, args)
+	if err != nil {
+		return nil, wrapLocated(err, m.name)
+	}
+
+	return expressionOf(v, expr.location())
+}
+
+// evalDefineMacro binds a procedural macro, given either as
+// (define-macro (name formals...) body...) or (define-macro name transformer).
+func evalDefineMacro(expr expression, env *frame) (value, error) {
+	c := mustExpressionChildren(expr)
+
+	var (
+		name        *tokenExpression
+		transformer value
+		err         error
+	)
+
+	switch first := c[1].(type) {
+	case *tokenExpression:
+		name = first
+		if transformer, err = eval(c[2], env); err != nil {
+			return nil, err
+		}
+	case *compoundExpression:
+		name = first.children[0].(*tokenExpression)
+		if transformer, err = evalNewProc(first.children[1:], c[2:], env); err != nil {
+			return nil, err
+		}
+	}
+
+	if !IsProc(transformer) {
+		return nil, errs.WrapAfterf(errInvalidMacroTransformer, "got %s", typeName(transformer))
+	}
+
+	if proc, ok := transformer.(*procValue); ok && proc.name == "" {
+		proc.name = name.name()
+	}
+
+	env.set(name.token, &procMacroValue{name: name.name(), transformer: transformer})
+	return nullValue{}, nil
+}
+
+// expressionOf converts a datum back to the expression that denotes it, the
+// inverse of datum. The new expressions are attributed to the location at.
+func expressionOf(v value, at span) (expression, error) {
+	return expressionOfDatum(v, at, make(map[value]bool))
+}
+
+// expressionOfDatum converts v like expressionOf. The pairs and vectors being
+// converted are open, and finding one again within itself means that v is
+// cyclic, so has no expression.
+func expressionOfDatum(v value, at span, open map[value]bool) (expression, error) {
+	var token string
+
+	switch v := v.(type) {
+	case nullValue:
+		return &compoundExpression{span: at}, nil
+	case numberValue, bigValue, ratValue, floatValue:
+		token = reprNumber(v)
+	case boolValue:
+		token = "#f"
+		if v.underlying {
+			token = "#t"
+		}
+	case stringValue:
+		token = `"` + v.underlying + `"`
+	case charValue:
+		token = reprChar(v.underlying)
+	case *bytevectorValue:
+		token = reprBytevector(v.bytes)
+	case *symbolValue:
+		if !readsAsIdentifier(v.name) {
+			return nil, errs.WrapAfterf(errInvalidMacroResult, "symbol %q does not read as an identifier", v.name)
+		}
+		token = v.name
+	case *pairValue:
+		var children []expression
+
+		var rest value = v
+		for {
+			p, ok := rest.(*pairValue)
+			if !ok {
+				break
+			}
+
+			if open[p] {
+				return nil, errs.WrapAfterf(errInvalidMacroResult, "contains a cycle")
+			}
+			open[p] = true
+
+			c, err := expressionOfDatum(p.car, at, open)
+			if err != nil {
+				return nil, err
+			}
+
+			children = append(children, c)
+			rest = p.cdr
+		}
+
+		if _, ok := rest.(nullValue); !ok {
+			tail, err := expressionOfDatum(rest, at, open)
+			if err != nil {
+				return nil, err
+			}
+
+			children = append(children, &tokenExpression{token: ".", span: at}, tail)
+		}
+
+		for p := value(v); p != rest; p = p.(*pairValue).cdr {
+			delete(open, p)
+		}
+
+		return &compoundExpression{children: children, span: at}, nil
+	case *vectorValue:
+		if open[v] {
+			return nil, errs.WrapAfterf(errInvalidMacroResult, "contains a cycle")
+		}
+		open[v] = true
+		defer delete(open, v)
+
+		children := make([]expression, len(v.elems))
+		for i, e := range v.elems {
+			c, err := expressionOfDatum(e, at, open)
+			if err != nil {
+				return nil, err
+			}
+			children[i] = c
+		}
+
+		return &compoundExpression{children: children, span: at, vector: true}, nil
+	default:
+		return nil, errs.WrapAfterf(errInvalidMacroResult, "contains %s", typeName(v))
+	}
+
+	return &tokenExpression{token: token, span: at}, nil
+}
+
+// readsAsIdentifier reports whether name, written out, reads back as an
+// identifier with that name, rather than as a literal, several tokens or
+// none.
+func readsAsIdentifier(name string) bool {
+	exprs, err := parseSource("", name)
+	if err != nil || len(exprs) != 1 {
+		return false
+	}
+
+	t, ok := exprs[0].(*tokenExpression)
+	if !ok || t.token != name || isDot(t) {
+		return false
+	}
+
+	k, err := classifyToken(t)
+	return err == nil && k == exprDereference
+}
@@ -0,0 +1,169 @@
+package scheme
+
+import (
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestDefineMacro(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{
+			src:  `(define-macro (my-if c a b) (list 'if c a b)) (my-if #t 1 2)`,
+			want: `1`,
+		},
+		{
+			src: `
+				(define expansions 0)
+				(define-macro (counted e) (set! expansions (+ expansions 1)) e)
+				(define (f x) (counted (* x 2)))
+				(list (f 1) (f 2) (f 3) expansions)`,
+			want: `(2 4 6 1)`,
+		},
+		{
+			src: `
+				(define-macro (m) 1)
+				(define (f) (m))
+				(define a (f))
+				(define-macro (m) 2)
+				(list a (f))`,
+			want: `(1 2)`,
+		},
+		{
+			src:  "(define-macro (swap-args f a b) `(,f ,b ,a)) (swap-args - 1 10)",
+			want: `9`,
+		},
+		{
+			src:  "(define-macro (my-begin . body) `((lambda () ,@body))) (my-begin 1 2 3)",
+			want: `3`,
+		},
+		{
+			src:  "(define-macro twice (lambda (e) `(begin ,e ,e))) (twice 5)",
+			want: `5`,
+		},
+		{
+			src:  "(define-macro (with-it v body) `(let ((it ,v)) ,body)) (with-it 42 it)",
+			want: `42`,
+		},
+		{
+			src:  "(define-macro (quote-it x) `',x) (quote-it (undefined thing . 1))",
+			want: `(undefined thing . 1)`,
+		},
+		{
+			src:  `(define-macro (str) "abc") (str)`,
+			want: `"abc"`,
+		},
+		{
+			src:  `(define-macro (empty) '()) (empty)`,
+			want: `()`,
+		},
+		{
+			src:  `(define-macro (m) 1) m`,
+			want: `#<macro m>`,
+		},
+		{
+			src:     `(define-macro m 5)`,
+			wantErr: errInvalidMacroTransformer,
+		},
+		{
+			src:     `(define-macro (m) car) (m)`,
+			wantErr: errInvalidMacroResult,
+		},
+		{
+			src:  `(define-macro (m) (list 'quote (list 'a (string->symbol "b")))) (m)`,
+			want: `(a b)`,
+		},
+		{
+			src:  `(define-macro (m) (let ((shared (list 1 2))) (list 'quote (list shared shared)))) (m)`,
+			want: `((1 2) (1 2))`,
+		},
+		{
+			src:     `(define-macro (m) (string->symbol "")) (m)`,
+			wantErr: errInvalidMacroResult,
+		},
+		{
+			src:     `(define-macro (m) (string->symbol "42")) (m)`,
+			wantErr: errInvalidMacroResult,
+		},
+		{
+			src:     `(define-macro (m) (string->symbol "#t")) (m)`,
+			wantErr: errInvalidMacroResult,
+		},
+		{
+			src:     `(define-macro (m) (string->symbol "null")) (m)`,
+			wantErr: errInvalidMacroResult,
+		},
+		{
+			src:     `(define-macro (m) (list 'quote (string->symbol "a b"))) (m)`,
+			wantErr: errInvalidMacroResult,
+		},
+		{
+			src:     `(define-macro (m) (list 'quote (string->symbol "("))) (m)`,
+			wantErr: errInvalidMacroResult,
+		},
+		{
+			src:     `(define-macro (m) (let ((l (list 1 2))) (set-cdr! (cdr l) l) (list 'quote l))) (m)`,
+			wantErr: errInvalidMacroResult,
+		},
+		{
+			src:     `(define-macro (m) (let ((l (list 1 2))) (set-car! l l) (list 'quote l))) (m)`,
+			wantErr: errInvalidMacroResult,
+		},
+		{
+			src:     `(define-macro (m) (let ((v (vector 1))) (vector-set! v 0 (list v)) (list 'quote v))) (m)`,
+			wantErr: errInvalidMacroResult,
+		},
+		{
+			src:     `(define-macro (m x) x) (m)`,
+			wantErr: errWrongNumberOfArguments,
+		},
+		{
+			src:     `(define-macro (m) (car 1)) (m)`,
+			wantErr: errInvalidArgumentType,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := NewInterpreter().Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
+		}
+	}
+}
+
+func TestDefineMacroErrorMessage(t *testing.T) {
+	cases := []struct {
+		src  string
+		want string
+	}{
+		{
+			src:  `(define-macro (m) (car 1)) (m)`,
+			want: `1:19: (car 1): m: bad argument type`,
+		},
+		{
+			src:  `(define-macro m car) (m)`,
+			want: `1:22: (m): m: application with wrong number of arguments`,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		_, err := NewInterpreter().Eval(c.src)
+		if err == nil || err.Error() != c.want {
+			t.Errorf("error:\ngot:  %v\nwant: %v", err, c.want)
+		}
+	}
+}
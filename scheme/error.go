@@ -0,0 +1,167 @@
+package scheme
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"scgeme/errs"
+)
+
+// maxBacktrace is the number of stack frames shown by Error.Backtrace.
+const maxBacktrace = 20
+
+// Error describes a failure during evaluation, along with the location of the
+// expression that caused it and the procedure calls in progress at the time.
+type Error struct {
+	err     error
+	span    span
+	snippet string
+	stack   []StackFrame
+}
+
+// StackFrame describes a procedure call that was in progress when an error
+// occurred. Calls made in tail position replace the frame of their caller, as
+// they do during evaluation.
+type StackFrame struct {
+	Proc   string  // name of the procedure, or "" if anonymous
+	Args   []Value // argument values
+	File   string  // location of the call
+	Line   int
+	Column int
+}
+
+func (f StackFrame) String() string {
+	parts := []string{f.Proc}
+	if f.Proc == "" {
+		parts[0] = "#<procedure>"
+	}
+
+	for _, a := range f.Args {
+		parts = append(parts, Repr(a))
+	}
+
+	call := "(" + strings.Join(parts, " ") + ")"
+	pos := position{file: f.File, line: f.Line, col: f.Column}
+
+	return fmt.Sprintf("%s at %s", call, pos)
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.span, e.snippet, e.err)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// File returns the name of the source file containing the failing expression,
+// as passed to Interpreter.EvalSource.
+func (e *Error) File() string {
+	return e.span.start.file
+}
+
+// Line returns the line on which the failing expression starts.
+func (e *Error) Line() int {
+	return e.span.start.line
+}
+
+// Column returns the column at which the failing expression starts.
+func (e *Error) Column() int {
+	return e.span.start.col
+}
+
+// Snippet returns an abbreviated rendering of the failing expression.
+func (e *Error) Snippet() string {
+	return e.snippet
+}
+
+// Stack returns the procedure calls that were in progress when the error
+// occurred, innermost first.
+func (e *Error) Stack() []StackFrame {
+	return e.stack
+}
+
+// Backtrace renders the stack, innermost call first, one call per line.
+func (e *Error) Backtrace() string {
+	var b strings.Builder
+
+	for i, f := range e.stack {
+		if i == maxBacktrace {
+			fmt.Fprintf(&b, "  ... %d more\n", len(e.stack)-i)
+			break
+		}
+
+		fmt.Fprintf(&b, "  %d: %s\n", i, f)
+	}
+
+	return b.String()
+}
+
+// locate attaches the location of expr to err, unless err already has a
+// location from a more deeply nested expression.
+func locate(err error, expr expression) error {
+	if err == nil {
+		return nil
+	}
+
+	var located *Error
+	if errors.As(err, &located) {
+		return err
+	}
+
+	return &Error{err: err, span: expr.location(), snippet: snippet(expr)}
+}
+
+// wrapLocated adds message to err like errs.Wrap, but after the location of
+// err if it has one, so that the location still comes first.
+func wrapLocated(err error, message string) error {
+	var located *Error
+	if errors.As(err, &located) {
+		located.err = errs.Wrap(located.err, message)
+		return err
+	}
+
+	return errs.Wrap(err, message)
+}
+
+// newStackFrame describes a call of fval with args from the application expr.
+// There is no call, and so no frame, if fval is not a procedure.
+func newStackFrame(fval value, args []value, expr expression) *StackFrame {
+	var name string
+
+	switch proc := fval.(type) {
+	case *procValue:
+		name = proc.name
+	case *builtinValue:
+		name = proc.name
+	default:
+		return nil
+	}
+
+	start := expr.location().start
+
+	return &StackFrame{
+		Proc:   name,
+		Args:   args,
+		File:   start.file,
+		Line:   start.line,
+		Column: start.col,
+	}
+}
+
+// pushCall records that err passed out of call. The outermost call is added
+// last.
+func pushCall(err error, call *StackFrame) error {
+	if err == nil || call == nil {
+		return err
+	}
+
+	var located *Error
+	if errors.As(err, &located) {
+		located.stack = append(located.stack, *call)
+	}
+
+	return err
+}
@@ -0,0 +1,124 @@
+package scheme
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestErrorStack(t *testing.T) {
+	src := `(define (inner x) (+ (car x) 1))
+(define (middle x) (+ 1 (inner x)))
+(define (outer x) (middle x))
+(outer 5)`
+
+	_, err := NewInterpreter().EvalSource("test.scm", src)
+
+	located, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("error should be *Error: %T: %v", err, err)
+	}
+
+	// outer's frame is replaced by its tail call to middle.
+	want := []StackFrame{
+		{Proc: "car", Args: []Value{Number(5)}, File: "test.scm", Line: 1, Column: 22},
+		{Proc: "inner", Args: []Value{Number(5)}, File: "test.scm", Line: 2, Column: 25},
+		{Proc: "middle", Args: []Value{Number(5)}, File: "test.scm", Line: 3, Column: 19},
+	}
+
+	if got := located.Stack(); !reflect.DeepEqual(got, want) {
+		t.Errorf("stack:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	wantTrace := "  0: (car 5) at test.scm:1:22\n" +
+		"  1: (inner 5) at test.scm:2:25\n" +
+		"  2: (middle 5) at test.scm:3:19\n"
+
+	if got := located.Backtrace(); got != wantTrace {
+		t.Errorf("backtrace:\ngot:\n%s\nwant:\n%s", got, wantTrace)
+	}
+}
+
+func TestErrorStackBuiltin(t *testing.T) {
+	in := NewInterpreter()
+	in.DefineFunc("fail", func(n int) error { return errApplicationOnNonProc })
+
+	_, err := in.EvalSource("test.scm", "((lambda (x) (fail x) x) 3)")
+
+	located, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("error should be *Error: %T: %v", err, err)
+	}
+
+	want := []StackFrame{
+		{Proc: "fail", Args: []Value{Number(3)}, File: "test.scm", Line: 1, Column: 14},
+		{Proc: "", Args: []Value{Number(3)}, File: "test.scm", Line: 1, Column: 1},
+	}
+
+	if got := located.Stack(); !reflect.DeepEqual(got, want) {
+		t.Errorf("stack:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	if got, want := located.Stack()[1].String(), "(#<procedure> 3) at test.scm:1:1"; got != want {
+		t.Errorf("frame:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestErrorBacktraceTruncated(t *testing.T) {
+	// car is applied directly in the tail position of (deep 0), so both
+	// calls are on the stack.
+	src := `(define (deep n) (if (= n 0) (car n) (+ 1 (deep (- n 1)))))
+(deep 30)`
+
+	_, err := NewInterpreter().EvalSource("test.scm", src)
+
+	located, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("error should be *Error: %T: %v", err, err)
+	}
+
+	if got, want := len(located.Stack()), 32; got != want {
+		t.Errorf("stack depth:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	lines := located.Backtrace()
+	if want := "  ... 12 more\n"; len(lines) < len(want) || lines[len(lines)-len(want):] != want {
+		t.Errorf("backtrace should be truncated:\n%s", lines)
+	}
+}
+
+func TestErrorStackCalls(t *testing.T) {
+	cases := []struct {
+		src  string
+		want []StackFrame
+	}{
+		// 1 is not a procedure, so there is no call to show.
+		{"(1 2)", nil},
+		{
+			"(define (f x) x)\n(f)",
+			[]StackFrame{{Proc: "f", Args: []Value{}, File: "test.scm", Line: 2, Column: 1}},
+		},
+		{
+			"(define (g) (f 1 2))\n(define (f x) x)\n(g)",
+			[]StackFrame{
+				{Proc: "f", Args: []Value{Number(1), Number(2)}, File: "test.scm", Line: 1, Column: 13},
+				{Proc: "g", Args: []Value{}, File: "test.scm", Line: 3, Column: 1},
+			},
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %s", i, c.src)
+
+		_, err := NewInterpreter().EvalSource("test.scm", c.src)
+
+		located, ok := err.(*Error)
+		if !ok {
+			t.Errorf("error should be *Error: %T: %v", err, err)
+			continue
+		}
+
+		if got := located.Stack(); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("stack:\ngot:  %v\nwant: %v", got, c.want)
+		}
+	}
+}
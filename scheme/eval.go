@@ -0,0 +1,608 @@
+package scheme
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errApplicationOnNonProc = errors.New("application operator must evaluate to proc")
+
+// eval evaluates expr within env. Expressions in tail position (the branches
+// of an if, the last expression of a begin, let or procedure body) are
+// evaluated by looping rather than recursing, so tail calls run in constant Go
+// stack space. Errors are annotated with the location of the innermost
+// expression that failed, and with the procedure calls that were in progress.
+func eval(expr expression, env *frame) (value, error) {
+	// The procedure whose body is being evaluated by this loop. Tail calls
+	// replace it, just as they replace its frame.
+	var call *StackFrame
+
+	for {
+		v, next, err := evalStep(expr, env)
+		if err != nil {
+			return nil, pushCall(locate(err, expr), call)
+		}
+
+		if next.expr == nil {
+			return v, nil
+		}
+
+		expr, env = next.expr, next.env
+		if next.call != nil {
+			call = next.call
+		}
+	}
+}
+
+// tail is an expression that remains to be evaluated in tail position.
+type tail struct {
+	expr expression
+	env  *frame
+	call *StackFrame // set if expr begins the body of a procedure
+}
+
+// evalStep evaluates expr within env. It either returns the value of expr, or
+// the expression in tail position that must be evaluated next.
+func evalStep(expr expression, env *frame) (value, tail, error) {
+	if m, ok := macroUse(expr, env); ok {
+		next, err := expandUse(expr, m, env)
+		return nil, tail{expr: next, env: env}, err
+	}
+
+	t, err := classify(expr)
+	if err != nil {
+		return nil, tail{}, err
+	}
+
+	var v value
+
+	switch t {
+	case exprNull:
+		return nullValue{}, tail{}, nil
+	case exprNumber:
+		v, err = evalNumber(expr, env)
+	case exprBoolean:
+		v = boolValue{mustExpressionToken(expr) == "#t"}
+	case exprString:
+		s := mustExpressionToken(expr)
+		v = newString(s[1 : len(s)-1])
+	case exprChar:
+		r, _ := parseChar(mustExpressionToken(expr))
+		v = charValue{r}
+	case exprBytevector:
+		b, _ := parseBytevector(mustExpressionToken(expr))
+		v = &bytevectorValue{bytes: b}
+	case exprVector:
+		v, err = vectorDatum(expr.(*compoundExpression))
+	case exprDereference:
+		v, err = resolve(expr.(*tokenExpression), env)
+	case exprDefine:
+		v, err = evalDefine(mustExpressionChildren(expr)[1:], env)
+	case exprSet:
+		v, err = evalSet(expr, env)
+	case exprBegin:
+		body := mustExpressionChildren(expr)[1:]
+		if len(body) == 0 {
+			return nullValue{}, tail{}, nil
+		}
+
+		next, err := evalBodyInit(body, env)
+		return nil, tail{expr: next, env: env}, err
+	case exprIf:
+		v, next, err := evalIf(expr, env)
+		return v, tail{expr: next, env: env}, err
+	case exprCond:
+		return evalCond(expr, env)
+	case exprCase:
+		return evalCase(expr, env)
+	case exprAnd, exprOr:
+		v, next, err := evalAndOr(expr, env, t == exprAnd)
+		return v, tail{expr: next, env: env}, err
+	case exprWhen, exprUnless:
+		v, next, err := evalWhen(expr, env, t == exprWhen)
+		return v, tail{expr: next, env: env}, err
+	case exprLambda:
+		v, err = evalLambda(expr, env)
+	case exprLet:
+		next, nextEnv, err := evalLet(expr, env)
+		return nil, tail{expr: next, env: nextEnv}, err
+	case exprLetStar:
+		next, nextEnv, err := evalLetStar(expr, env)
+		return nil, tail{expr: next, env: nextEnv}, err
+	case exprLetrec, exprLetrecStar:
+		next, nextEnv, err := evalLetrec(expr, env, t == exprLetrecStar)
+		return nil, tail{expr: next, env: nextEnv}, err
+	case exprPrimitive:
+		v, err = evalPrimitive(expr, env)
+	case exprQuote:
+		v, err = datum(mustExpressionChildren(expr)[1])
+	case exprQuasiquote:
+		v, err = evalQuasiquote(mustExpressionChildren(expr)[1], 1, env)
+	case exprDefineSyntax:
+		v, err = evalDefineSyntax(expr, env)
+	case exprDefineMacro:
+		v, err = evalDefineMacro(expr, env)
+	case exprLetSyntax, exprLetrecSyntax:
+		next, nextEnv, err := evalLetSyntax(expr, env, t == exprLetrecSyntax)
+		return nil, tail{expr: next, env: nextEnv}, err
+	case exprApplication:
+		fval, args, err := evalOperands(expr, env)
+		if err != nil {
+			return nil, tail{}, err
+		}
+
+		return applyTail(fval, args, expr)
+	default:
+		panic(fmt.Sprintf("classified type cannot be evaluated: %d", t))
+	}
+
+	if err != nil {
+		return nil, tail{}, err
+	}
+
+	return v, tail{}, nil
+}
+
+// applyTail calls fval with args, as the application expr. The body of a
+// procedure is returned to be evaluated in tail position, while other
+// procedures are applied directly.
+func applyTail(fval value, args []value, expr expression) (value, tail, error) {
+	call := newStackFrame(fval, args, expr)
+
+	proc, ok := fval.(*procValue)
+	if !ok || len(proc.body) == 0 {
+		v, err := apply(fval, args)
+		if err != nil {
+			return nil, tail{}, pushCall(locate(err, expr), call)
+		}
+		return v, tail{}, nil
+	}
+
+	nextEnv, err := proc.bind(args)
+	if err != nil {
+		return nil, tail{}, pushCall(locate(err, expr), call)
+	}
+
+	next, err := evalBodyInit(proc.body, nextEnv)
+	if err != nil {
+		return nil, tail{}, pushCall(err, call)
+	}
+	return nil, tail{expr: next, env: nextEnv, call: call}, nil
+}
+
+func evalNumber(expr expression, env *frame) (value, error) {
+	num, ok := parseNumber(mustExpressionToken(expr), 10)
+	if !ok {
+		panic(fmt.Sprintf("value %v should be valid number", expr))
+	}
+	return num, nil
+}
+
+func evalDefine(exprs []expression, env *frame) (value, error) {
+	switch first := exprs[0].(type) {
+	case *tokenExpression:
+		v, err := eval(exprs[1], env)
+		if err != nil {
+			return nil, err
+		}
+
+		bind(env, first, v)
+		return nullValue{}, nil
+
+	case *compoundExpression:
+		name := first.children[0].(*tokenExpression)
+
+		proc, err := evalNewProc(first.children[1:], exprs[1:], env)
+		if err != nil {
+			return nil, err
+		}
+
+		proc.(*procValue).name = name.name()
+		env.set(name.token, proc)
+		return nullValue{}, nil
+
+	default:
+		panic(fmt.Sprintf("invalid define expression: %v", exprs))
+	}
+}
+
+// evalSet evaluates (set! identifier expression), which changes the value of
+// an existing binding, wherever it was made, rather than creating a new one.
+func evalSet(expr expression, env *frame) (value, error) {
+	c := mustExpressionChildren(expr)
+
+	v, err := eval(c[2], env)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := assignIdentifier(c[1].(*tokenExpression), v, env); err != nil {
+		return nil, err
+	}
+
+	return nullValue{}, nil
+}
+
+// evalIf evaluates the predicate of an if expression and returns the branch
+// to evaluate next, or an unspecified value if the predicate is false and
+// there is no alternative.
+func evalIf(expr expression, env *frame) (value, expression, error) {
+	c := mustExpressionChildren(expr)
+
+	p, err := eval(c[1], env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case isTrue(p):
+		return nil, c[2], nil
+	case len(c) == 4:
+		return nil, c[3], nil
+	default:
+		return nullValue{}, nil, nil
+	}
+}
+
+// evalCond evaluates the tests of a cond expression in turn, and selects the
+// clause of the first one that is true, as evalClause.
+func evalCond(expr expression, env *frame) (value, tail, error) {
+	for _, clause := range mustExpressionChildren(expr)[1:] {
+		c := mustExpressionChildren(clause)
+
+		var v value = boolValue{true}
+		if !isKeyword(c[0], "else") {
+			var err error
+			if v, err = eval(c[0], env); err != nil {
+				return nil, tail{}, err
+			}
+
+			if !isTrue(v) {
+				continue
+			}
+		}
+
+		return evalClause(v, clause, c[1:], env)
+	}
+
+	return nullValue{}, tail{}, nil
+}
+
+// evalCase evaluates the key of a case expression, and selects the first
+// clause listing a datum equal to it, as evalClause.
+func evalCase(expr expression, env *frame) (value, tail, error) {
+	c := mustExpressionChildren(expr)
+
+	key, err := eval(c[1], env)
+	if err != nil {
+		return nil, tail{}, err
+	}
+
+	for _, clause := range c[2:] {
+		cc := mustExpressionChildren(clause)
+
+		if !isKeyword(cc[0], "else") {
+			match, err := caseMatches(key, mustExpressionChildren(cc[0]))
+			if err != nil {
+				return nil, tail{}, err
+			}
+
+			if !match {
+				continue
+			}
+		}
+
+		return evalClause(key, clause, cc[1:], env)
+	}
+
+	return nullValue{}, tail{}, nil
+}
+
+func caseMatches(key value, datums []expression) (bool, error) {
+	for _, d := range datums {
+		v, err := datum(d)
+		if err != nil {
+			return false, err
+		}
+
+		if eq, _ := key.equals(v); eq {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// evalClause evaluates the body of the cond or case clause selected by v. A
+// clause without a body returns v itself, and a body of the form
+// "=> receiver" applies receiver to v, as a tail call. Otherwise the last body
+// expression is returned to be evaluated next.
+func evalClause(v value, clause expression, body []expression, env *frame) (value, tail, error) {
+	if len(body) == 0 {
+		return v, tail{}, nil
+	}
+
+	if isKeyword(body[0], "=>") {
+		f, err := eval(body[1], env)
+		if err != nil {
+			return nil, tail{}, err
+		}
+
+		return applyTail(f, []value{v}, clause)
+	}
+
+	next, err := evalBodyInit(body, env)
+	return nil, tail{expr: next, env: env}, err
+}
+
+// evalWhen evaluates the test of a when expression, or an unless expression if
+// want is false, and returns the last body expression to evaluate next if the
+// test is as wanted.
+func evalWhen(expr expression, env *frame, want bool) (value, expression, error) {
+	c := mustExpressionChildren(expr)
+
+	p, err := eval(c[1], env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if isTrue(p) != want {
+		return nullValue{}, nil, nil
+	}
+
+	next, err := evalBodyInit(c[2:], env)
+	return nil, next, err
+}
+
+// evalAndOr evaluates the operands of an and expression, or an or expression
+// if and is false, from left to right. It stops at the first operand that is
+// false (for and) or true (for or), returning its value, and otherwise returns
+// the last operand to evaluate next. With no operands, and is true and or is
+// false.
+func evalAndOr(expr expression, env *frame, and bool) (value, expression, error) {
+	operands := mustExpressionChildren(expr)[1:]
+	if len(operands) == 0 {
+		return boolValue{and}, nil, nil
+	}
+
+	for _, o := range operands[:len(operands)-1] {
+		v, err := eval(o, env)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if isTrue(v) != and {
+			return v, nil, nil
+		}
+	}
+
+	return nil, operands[len(operands)-1], nil
+}
+
+func evalLambda(expr expression, env *frame) (value, error) {
+	c := mustExpressionChildren(expr)
+	return evalNewProc(mustExpressionChildren(c[1]), c[2:], env)
+}
+
+// evalLet binds the variables of a let expression in a new frame, evaluates
+// all but the last body expression there, and returns the last body expression
+// along with the new frame.
+func evalLet(expr expression, env *frame) (expression, *frame, error) {
+	c := mustExpressionChildren(expr)
+
+	if name, ok := c[1].(*tokenExpression); ok {
+		return evalNamedLet(expr, name, c[2], c[3:], env)
+	}
+
+	nextEnv := env.extend()
+	for _, a := range mustExpressionChildren(c[1]) {
+		aexprs := mustExpressionChildren(a)
+
+		rval, err := eval(aexprs[1], env)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		bind(nextEnv, aexprs[0], rval)
+	}
+
+	tail, err := evalBodyInit(c[2:], nextEnv)
+	return tail, nextEnv, err
+}
+
+// evalNamedLet evaluates (let name bindings body...) by binding name, within
+// the body, to a procedure taking the bound variables as parameters, and
+// calling it with their initial values.
+func evalNamedLet(expr expression, name *tokenExpression, assignments expression, body []expression, env *frame) (expression, *frame, error) {
+	loopEnv := env.extend()
+	proc := &procValue{name: name.name(), body: body, env: loopEnv}
+	loopEnv.set(name.token, proc)
+
+	var args []value
+	for _, a := range mustExpressionChildren(assignments) {
+		aexprs := mustExpressionChildren(a)
+
+		rval, err := eval(aexprs[1], env)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		proc.formals = append(proc.formals, mustExpressionToken(aexprs[0]))
+		args = append(args, rval)
+	}
+
+	nextEnv, err := proc.bind(args)
+	if err != nil {
+		return nil, nil, pushCall(locate(err, expr), newStackFrame(proc, args, expr))
+	}
+
+	tail, err := evalBodyInit(body, nextEnv)
+	return tail, nextEnv, err
+}
+
+// evalLetStar is like evalLet, except that each binding is made in a new
+// frame extending that of the previous one, so initial values may refer to
+// the variables bound before them.
+func evalLetStar(expr expression, env *frame) (expression, *frame, error) {
+	c := mustExpressionChildren(expr)
+
+	nextEnv := env.extend()
+	for _, a := range mustExpressionChildren(c[1]) {
+		aexprs := mustExpressionChildren(a)
+
+		rval, err := eval(aexprs[1], nextEnv)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		nextEnv = nextEnv.extend()
+		bind(nextEnv, aexprs[0], rval)
+	}
+
+	tail, err := evalBodyInit(c[2:], nextEnv)
+	return tail, nextEnv, err
+}
+
+// evalLetrec is like evalLet, except that initial values are evaluated within
+// the new frame, so they may refer to each other. The variables are bound but
+// unassigned until then. For letrec all initial values are evaluated before any
+// variable is assigned; for letrec*, sequential is true and each variable is
+// assigned as soon as its value is known.
+func evalLetrec(expr expression, env *frame, sequential bool) (expression, *frame, error) {
+	c := mustExpressionChildren(expr)
+	assignments := mustExpressionChildren(c[1])
+
+	nextEnv := env.extend()
+	for _, a := range assignments {
+		nextEnv.set(mustExpressionChildren(a)[0].(*tokenExpression).token, unassigned)
+	}
+
+	rvals := make([]value, len(assignments))
+	for i, a := range assignments {
+		aexprs := mustExpressionChildren(a)
+
+		rval, err := eval(aexprs[1], nextEnv)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if sequential {
+			bind(nextEnv, aexprs[0], rval)
+		}
+		rvals[i] = rval
+	}
+
+	if !sequential {
+		for i, a := range assignments {
+			bind(nextEnv, mustExpressionChildren(a)[0], rvals[i])
+		}
+	}
+
+	tail, err := evalBodyInit(c[2:], nextEnv)
+	return tail, nextEnv, err
+}
+
+// bind binds the identifier to v in env. Anonymous procedures take the first
+// name they are bound to, for use in stack traces.
+func bind(env *frame, identifier expression, v value) {
+	t := identifier.(*tokenExpression)
+
+	if proc, ok := v.(*procValue); ok && proc.name == "" {
+		proc.name = t.name()
+	}
+
+	env.set(t.token, v)
+}
+
+func evalDefineSyntax(expr expression, env *frame) (value, error) {
+	c := mustExpressionChildren(expr)
+	name := c[1].(*tokenExpression)
+
+	m, err := newMacro(name.name(), c[2], env)
+	if err != nil {
+		return nil, err
+	}
+
+	env.set(name.token, m)
+	return nullValue{}, nil
+}
+
+// evalLetSyntax binds the macros of a let-syntax or letrec-syntax expression
+// in a new frame, and returns the last body expression along with the frame
+// like evalLet. The macros of a letrec-syntax are defined within the new frame,
+// so they may refer to each other.
+func evalLetSyntax(expr expression, env *frame, rec bool) (expression, *frame, error) {
+	c := mustExpressionChildren(expr)
+
+	nextEnv := env.extend()
+	macroEnv := env
+	if rec {
+		macroEnv = nextEnv
+	}
+
+	for _, a := range mustExpressionChildren(c[1]) {
+		aexprs := mustExpressionChildren(a)
+		name := aexprs[0].(*tokenExpression)
+
+		m, err := newMacro(name.name(), aexprs[1], macroEnv)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		nextEnv.set(name.token, m)
+	}
+
+	tail, err := evalBodyInit(c[2:], nextEnv)
+	return tail, nextEnv, err
+}
+
+func evalPrimitive(expr expression, env *frame) (value, error) {
+	c := mustExpressionChildren(expr)
+	f, ok := primitives[mustExpressionToken(c[1])]
+	if !ok {
+		return nil, errInvalidCompoundExpression
+	}
+
+	args, err := mapEval(c[2:], env)
+	if err != nil {
+		return nil, err
+	}
+
+	return f(args)
+}
+
+// evalOperands evaluates the operator and arguments of an application.
+func evalOperands(expr expression, env *frame) (value, []value, error) {
+	c := mustExpressionChildren(expr)
+
+	fval, err := eval(c[0], env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args, err := mapEval(c[1:], env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fval, args, nil
+}
+
+// apply calls the procedure fval with already-evaluated arguments.
+func apply(fval value, args []value) (value, error) {
+	switch proc := fval.(type) {
+	case *procValue:
+		nextEnv, err := proc.bind(args)
+		if err != nil {
+			return nil, err
+		}
+
+		return evalSequence(proc.body, nextEnv)
+
+	case *builtinValue:
+		return proc.fn(args)
+
+	default:
+		return nil, errApplicationOnNonProc
+	}
+}
@@ -1,7 +1,7 @@
-package main
+package scheme
 
 func mapEval(exprs []expression, env *frame) ([]value, error) {
-	var res []value
+	res := make([]value, 0, len(exprs))
 	for _, c := range exprs {
 		v, err := eval(c, env)
 		if err != nil {
@@ -25,6 +25,17 @@ func evalSequence(exprs []expression, env *frame) (value, error) {
 	return values[len(values)-1], nil
 }
 
+// evalBodyInit evaluates all but the last of a non-empty sequence of
+// expressions and returns the last one, so that the caller can evaluate it in
+// tail position.
+func evalBodyInit(exprs []expression, env *frame) (expression, error) {
+	if _, err := mapEval(exprs[:len(exprs)-1], env); err != nil {
+		return nil, err
+	}
+
+	return exprs[len(exprs)-1], nil
+}
+
 func evalNewProc(paramExprs []expression, body []expression, env *frame) (value, error) {
 	var (
 		pv   = &procValue{body: body, env: env}
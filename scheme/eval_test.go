@@ -0,0 +1,707 @@
+package scheme
+
+import (
+	"reflect"
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestEval(t *testing.T) {
+	env := newFrame()
+	env.set("testVar", numberValue{1})
+	env.set("testProc", &procValue{
+		formals: []string{"x"},
+		body:    []expression{&tokenExpression{token: "x"}},
+	})
+	env.set("testRest", &procValue{
+		formals: []string{"x"},
+		rest:    "y",
+		body: []expression{&compoundExpression{
+			children: []expression{
+				&tokenExpression{token: "primitive"},
+				&tokenExpression{token: "cons"},
+				&tokenExpression{token: "x"},
+				&tokenExpression{token: "y"},
+			},
+		}},
+	})
+
+	cases := []struct {
+		src     string
+		want    value
+		wantErr error
+	}{
+		{
+			src:  `null`,
+			want: nullValue{},
+		},
+		{
+			src:  `1`,
+			want: numberValue{1},
+		},
+		{
+			src:  `#t`,
+			want: boolValue{true},
+		},
+		{
+			src:  `#f`,
+			want: boolValue{false},
+		},
+		{
+			src:  `"foo"`,
+			want: newString("foo"),
+		},
+		{
+			src:  `"foo bar"`,
+			want: newString("foo bar"),
+		},
+		{
+			src:  `"foo\nbar\""`,
+			want: newString("foo\nbar\""),
+		},
+		{
+			src:  `""`,
+			want: stringValue{},
+		},
+		{
+			src:  `testVar`,
+			want: numberValue{1},
+		},
+		{
+			src:  `(begin)`,
+			want: nullValue{},
+		},
+		{
+			src:  `(begin 1 2)`,
+			want: numberValue{2},
+		},
+		{
+			src:  `(if #t 1 2)`,
+			want: numberValue{1},
+		},
+		{
+			src:  `(if #f 1 2)`,
+			want: numberValue{2},
+		},
+		{
+			src:  `(if 0 1 2)`,
+			want: numberValue{1},
+		},
+		{
+			src:  `(if #t 1)`,
+			want: numberValue{1},
+		},
+		{
+			src:  `(if #f 1)`,
+			want: nullValue{},
+		},
+		{
+			src: `(lambda () null)`,
+			want: &procValue{
+				formals: nil,
+				body:    []expression{&tokenExpression{token: "null"}},
+				env:     env,
+			},
+		},
+		{
+			src: `(lambda (a b) a b)`,
+			want: &procValue{
+				formals: []string{"a", "b"},
+				body: []expression{
+					&tokenExpression{token: "a"},
+					&tokenExpression{token: "b"},
+				},
+				env: env,
+			},
+		},
+		{
+			src: `(lambda (. x) x)`,
+			want: &procValue{
+				rest: "x",
+				body: []expression{
+					&tokenExpression{token: "x"},
+				},
+				env: env,
+			},
+		},
+		{
+			src: `(lambda (x . y) x)`,
+			want: &procValue{
+				formals: []string{"x"},
+				rest:    "y",
+				body: []expression{
+					&tokenExpression{token: "x"},
+				},
+				env: env,
+			},
+		},
+		{
+			src: `(lambda (x y . z) x)`,
+			want: &procValue{
+				formals: []string{"x", "y"},
+				rest:    "z",
+				body: []expression{
+					&tokenExpression{token: "x"},
+				},
+				env: env,
+			},
+		},
+		{
+			src:     `(lambda (.) x)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(lambda (. .) x)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(lambda (. x y) x)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(lambda (. x .) x)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(lambda ((a)) 1)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  `(let ((a 1)) a)`,
+			want: numberValue{1},
+		},
+		{
+			src: `
+				(let ((a 1)
+					    (b 2))
+					a
+					b)
+			`,
+			want: numberValue{2},
+		},
+		{
+			src:  `(primitive + 1 2)`,
+			want: numberValue{3},
+		},
+		{
+			src:  `(testProc #t)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(testRest 1 2 3)`,
+			want: makeList([]value{numberValue{1}, numberValue{2}, numberValue{3}}),
+		},
+		{
+			src: `
+				(((lambda (x)
+					(lambda (y)
+						(primitive + x y)))
+				  2)
+				 1)
+			`,
+			want: numberValue{3},
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		exprs, err := parseSource("", c.src)
+		if err != nil {
+			t.Fatal("parse error:", err)
+		}
+		stripSpans(exprs)
+
+		if len(exprs) != 1 {
+			t.Fatal("should be exactly one top-level expression: ", exprs)
+		}
+
+		got, gotErr := eval(exprs[0], env)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("value:\ngot:  %v\nwant: %v", got, c.want)
+		}
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+		}
+	}
+}
+
+// define tests are separate because they modify the environment.
+func TestEvalDefine(t *testing.T) {
+	env := newFrame()
+	env.set("testVar", numberValue{1})
+	env.set("testProc", &procValue{
+		formals: []string{"x"},
+		body:    []expression{&tokenExpression{token: "x"}},
+	})
+
+	cases := []struct {
+		src       string
+		wantBound map[string]value
+		wantErr   error
+	}{
+		{
+			src: `(define a 1)`,
+			wantBound: map[string]value{
+				"a": numberValue{1},
+			},
+		},
+		{
+			src: `(define a testVar)`,
+			wantBound: map[string]value{
+				"a": numberValue{1},
+			},
+		},
+		{
+			src: `(define a testVar)`,
+			wantBound: map[string]value{
+				"a": numberValue{1},
+			},
+		},
+		{
+			src: `(define a (testProc 1))`,
+			wantBound: map[string]value{
+				"a": numberValue{1},
+			},
+		},
+		{
+			src: `(define a (lambda (x) x))`,
+			wantBound: map[string]value{
+				"a": &procValue{
+					name:    "a",
+					formals: []string{"x"},
+					body:    []expression{&tokenExpression{token: "x"}},
+					// env will be set by test harness
+				},
+			},
+		},
+		{
+			src: `(define (a x) x)`,
+			wantBound: map[string]value{
+				"a": &procValue{
+					name:    "a",
+					formals: []string{"x"},
+					body:    []expression{&tokenExpression{token: "x"}},
+					// env will be set by test harness
+				},
+			},
+		},
+		{
+			src: `(define (a) 1)`,
+			wantBound: map[string]value{
+				"a": &procValue{
+					name:    "a",
+					formals: nil,
+					body:    []expression{&tokenExpression{token: "1"}},
+					// env will be set by test harness
+				},
+			},
+		},
+		{
+			src: `(define (a x y) (+ x y))`,
+			wantBound: map[string]value{
+				"a": &procValue{
+					name:    "a",
+					formals: []string{"x", "y"},
+					body: []expression{
+						&compoundExpression{
+							children: []expression{
+								&tokenExpression{token: "+"},
+								&tokenExpression{token: "x"},
+								&tokenExpression{token: "y"},
+							},
+						},
+					},
+					// env will be set by test harness
+				},
+			},
+		},
+		{
+			src: `(define (a x y) x y)`,
+			wantBound: map[string]value{
+				"a": &procValue{
+					name:    "a",
+					formals: []string{"x", "y"},
+					body:    []expression{&tokenExpression{token: "x"}, &tokenExpression{token: "y"}},
+					// env will be set by test harness
+				},
+			},
+		},
+		{
+			src: `(define (a . x) x)`,
+			wantBound: map[string]value{
+				"a": &procValue{
+					name: "a",
+					rest: "x",
+					body: []expression{&tokenExpression{token: "x"}},
+					// env will be set by test harness
+				},
+			},
+		},
+		{
+			src: `(define (a x . y) x)`,
+			wantBound: map[string]value{
+				"a": &procValue{
+					name:    "a",
+					formals: []string{"x"},
+					rest:    "y",
+					body:    []expression{&tokenExpression{token: "x"}},
+					// env will be set by test harness
+				},
+			},
+		},
+		{
+			src: `(define (a x y . z) x)`,
+			wantBound: map[string]value{
+				"a": &procValue{
+					name:    "a",
+					formals: []string{"x", "y"},
+					rest:    "z",
+					body:    []expression{&tokenExpression{token: "x"}},
+					// env will be set by test harness
+				},
+			},
+		},
+		{
+			src:     `(define (a .) x)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(define (a . .) x)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(define (a . x y) x)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `(define (a . x .) x)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		exprs, err := parseSource("", c.src)
+		if err != nil {
+			t.Fatal("parse error:", err)
+		}
+		stripSpans(exprs)
+
+		if len(exprs) != 1 {
+			t.Fatal("should be exactly one top-level expression: ", exprs)
+		}
+
+		caseEnv := env.extend()
+		got, gotErr := eval(exprs[0], caseEnv)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && !reflect.DeepEqual(got, nullValue{}) {
+			t.Errorf("value:\ngot:  %v\nwant: %v", got, nullValue{})
+		}
+
+		if gotErr != nil && got != nil {
+			t.Errorf("value:\ngot:  %v\nwant: nil", got)
+		}
+
+		if len(c.wantBound) > 0 {
+			for k, v := range c.wantBound {
+				// Proc bindings will expect the per-case environment.
+				if proc, ok := v.(*procValue); ok {
+					proc.env = caseEnv
+				}
+
+				got, err := caseEnv.get(k)
+				if err != nil {
+					t.Errorf("binding %s: not found", k)
+				}
+
+				if !reflect.DeepEqual(got, v) {
+					t.Errorf("binding:\ngot:  %v\nwant: %v", got, v)
+				}
+			}
+		}
+	}
+}
+
+func TestEvalConditionals(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{
+			src:  `(cond (#f 1) (#t 2) (else 3))`,
+			want: `2`,
+		},
+		{
+			src:  `(cond (#f 1) (else 2 3))`,
+			want: `3`,
+		},
+		{
+			src:  `(cond (#f 1))`,
+			want: `()`,
+		},
+		{
+			src:  `(cond ((car '(5))))`,
+			want: `5`,
+		},
+		{
+			src:  `(cond ((cdr '(1 2)) => car) (else 3))`,
+			want: `2`,
+		},
+		{
+			src:  `(cond (#f 1) (else => (lambda (x) x)))`,
+			want: `#t`,
+		},
+		{
+			src:  `(case (+ 1 2) ((1 2) 'low) ((3 4) 'mid) (else 'high))`,
+			want: `mid`,
+		},
+		{
+			src:  `(case 'b ((a) 1) ((b c) 2))`,
+			want: `2`,
+		},
+		{
+			src:  `(case "x" (("x") 1) (else 2))`,
+			want: `1`,
+		},
+		{
+			src:  `(case 9 ((1) 1) (else => (lambda (x) (* x 2))))`,
+			want: `18`,
+		},
+		{
+			src:  `(case 9 ((1) 1))`,
+			want: `()`,
+		},
+		{
+			src:  `(when (= 1 1) 1 2)`,
+			want: `2`,
+		},
+		{
+			src:  `(when #f undefined)`,
+			want: `()`,
+		},
+		{
+			src:  `(unless #f 1 2)`,
+			want: `2`,
+		},
+		{
+			src:  `(unless 1 undefined)`,
+			want: `()`,
+		},
+		{
+			src:  `(cond (#f undefined) (#t 1))`,
+			want: `1`,
+		},
+		{
+			src:     `(cond (undefined 1))`,
+			wantErr: errBindingNotFound,
+		},
+		{
+			src:     `(cond (#t => 1))`,
+			wantErr: errApplicationOnNonProc,
+		},
+		{
+			src:     `(case 1 (else 1) ((1) 2))`,
+			wantErr: errInvalidClause,
+		},
+		{
+			src: `
+				(define (count n)
+				  (cond ((= n 0) 'done)
+				        (else (count (- n 1)))))
+				(count 100000)`,
+			want: `done`,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := NewInterpreter().Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
+		}
+	}
+}
+
+func TestEvalLetForms(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{
+			src:  `(let* ((a 1) (b (+ a 1)) (a (* b 10))) (list a b))`,
+			want: `(20 2)`,
+		},
+		{
+			src:  `(let* () 1)`,
+			want: `1`,
+		},
+		{
+			src:     `(let ((a 1) (b (+ a 1))) b)`,
+			wantErr: errBindingNotFound,
+		},
+		{
+			src: `
+				(letrec ((even? (lambda (n) (if (= n 0) #t (odd? (- n 1)))))
+				         (odd? (lambda (n) (if (= n 0) #f (even? (- n 1))))))
+				  (list (even? 10) (odd? 10)))`,
+			want: `(#t #f)`,
+		},
+		{
+			src:  `(letrec ((f (lambda () 1))) f)`,
+			want: `#<procedure f>`,
+		},
+		{
+			src:     `(letrec ((a 1) (b (+ a 1))) b)`,
+			wantErr: errUnassigned,
+		},
+		{
+			src:     `(let ((a 100)) (letrec ((a 1) (b a)) b))`,
+			wantErr: errUnassigned,
+		},
+		{
+			src:  `(let ((a 100)) (letrec ((a 1) (b (lambda () a))) (b)))`,
+			want: `1`,
+		},
+		{
+			src:  `(letrec* ((a 1) (b (+ a 1))) b)`,
+			want: `2`,
+		},
+		{
+			src:     `(let ((b 100)) (letrec* ((a b) (b 1)) a))`,
+			wantErr: errUnassigned,
+		},
+		{
+			src: `
+				(letrec* ((fact (lambda (n) (if (= n 0) 1 (* n (fact (- n 1))))))
+				          (x (fact 5)))
+				  x)`,
+			want: `120`,
+		},
+		{
+			src:  `(let loop ((i 0) (acc '())) (if (= i 3) acc (loop (+ i 1) (cons i acc))))`,
+			want: `(2 1 0)`,
+		},
+		{
+			src:  `(let loop () 1)`,
+			want: `1`,
+		},
+		{
+			src:  `(let loop ((i 0)) loop)`,
+			want: `#<procedure loop>`,
+		},
+		{
+			src:  `(let ((loop 1)) (let loop ((i loop)) i))`,
+			want: `1`,
+		},
+		{
+			src:  `(let loop ((i 0)) (if (= i 100000) 'done (loop (+ i 1))))`,
+			want: `done`,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := NewInterpreter().Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
+		}
+	}
+}
+
+func TestEvalSet(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{
+			src:  `(define x 1) (set! x 2) x`,
+			want: `2`,
+		},
+		{
+			src: `
+				(define (make-counter)
+				  (let ((n 0))
+				    (lambda () (set! n (+ n 1)) n)))
+				(define c (make-counter))
+				(c)
+				(c)
+				(list (c) ((make-counter)))`,
+			want: `(3 1)`,
+		},
+		{
+			src:  `(define x 1) (define (f) (set! x 5)) (f) x`,
+			want: `5`,
+		},
+		{
+			src:  `(define x 1) (let ((x 2)) (set! x 3)) x`,
+			want: `1`,
+		},
+		{
+			src: `
+				(define-syntax swap!
+				  (syntax-rules ()
+				    ((_ a b) (let ((tmp a)) (set! a b) (set! b tmp)))))
+				(define tmp 1)
+				(define y 2)
+				(swap! tmp y)
+				(list tmp y)`,
+			want: `(2 1)`,
+		},
+		{
+			src: `
+				(define count 0)
+				(define-syntax bump! (syntax-rules () ((_) (set! count (+ count 1)))))
+				(let ((count 10)) (bump!) (bump!))
+				count`,
+			want: `2`,
+		},
+		{
+			src:     `(set! x 1) (= 1 1)`,
+			wantErr: errBindingNotFound,
+		},
+		{
+			src:     `(set! x)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := NewInterpreter().Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package scheme
+
+import (
+	"errors"
+	"fmt"
+
+	"scgeme/errs"
+)
+
+var (
+	errBindingNotFound = errors.New("environment does not contain binding")
+	errUnassigned      = errors.New("variable used before it is assigned")
+)
+
+// unassigned is bound to the variables of a letrec while their inits are
+// evaluated, so that they shadow any outer bindings but cannot be referenced.
+var unassigned = &unassignedValue{}
+
+type unassignedValue struct {
+}
+
+func (_ *unassignedValue) valueType() {
+	// does nothing
+}
+
+func (v *unassignedValue) equals(other value) (bool, error) {
+	return v == other, nil
+}
+
+type frame struct {
+	parent *frame
+	table  map[string]value
+}
+
+func newFrame() *frame {
+	f := new(frame)
+	f.table = make(map[string]value)
+	return f
+}
+
+func (f *frame) get(k string) (value, error) {
+	if v, ok := f.lookup(k); ok {
+		return v, nil
+	}
+
+	return nil, errs.WrapAfterf(errBindingNotFound, "%q", k)
+}
+
+// lookup returns the value bound to k in f or the nearest of its ancestors.
+func (f *frame) lookup(k string) (value, bool) {
+	for ; f != nil; f = f.parent {
+		if v, ok := f.table[k]; ok {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// owner returns the frame, f or the nearest of its ancestors, that binds k, or
+// nil if none does.
+func (f *frame) owner(k string) *frame {
+	for ; f != nil; f = f.parent {
+		if _, ok := f.table[k]; ok {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// set binds k to v in f itself, shadowing any binding in its ancestors.
+func (f *frame) set(k string, v value) {
+	f.table[k] = v
+}
+
+// assign rebinds k to v in the nearest frame, f or one of its ancestors, that
+// binds it. It is an error if no frame does.
+func (f *frame) assign(k string, v value) error {
+	for e := f; e != nil; e = e.parent {
+		if _, ok := e.table[k]; ok {
+			e.table[k] = v
+			return nil
+		}
+	}
+
+	return errs.WrapAfterf(errBindingNotFound, "%q", k)
+}
+
+// copy returns a frame with the same parent as f and its own copy of f's
+// bindings, so that assignments to either do not affect the other.
+func (f *frame) copy() *frame {
+	res := newFrame()
+	res.parent = f.parent
+	for k, v := range f.table {
+		res.table[k] = v
+	}
+	return res
+}
+
+func (f *frame) extend() *frame {
+	res := newFrame()
+	res.parent = f
+	return res
+}
+
+func (f *frame) debug() string {
+	res := ""
+	i := 0
+
+	for f != nil {
+		res += fmt.Sprintf("-- frame %d --\n", i)
+
+		for k, v := range f.table {
+			res += fmt.Sprintf("%s: %+v", k, v)
+		}
+
+		f = f.parent
+		i += 1
+	}
+
+	return res
+}
@@ -1,4 +1,4 @@
-package main
+package scheme
 
 import (
 	"scgeme/errs"
@@ -50,4 +50,22 @@ func TestFrame(t *testing.T) {
 	if errs.Root(err) != errBindingNotFound {
 		t.Errorf("error:\ngot:  %v\nwant: %v", errs.Root(err), errBindingNotFound)
 	}
+
+	err = f2.assign("number", numberValue{1})
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	v, err = f1.get("number")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+	if ok, _ := v.equals(numberValue{1}); !ok {
+		t.Errorf("assignment not visible in defining frame")
+	}
+
+	err = f1.assign("bool", boolValue{})
+	if errs.Root(err) != errBindingNotFound {
+		t.Errorf("error:\ngot:  %v\nwant: %v", errs.Root(err), errBindingNotFound)
+	}
 }
@@ -0,0 +1,189 @@
+package scheme
+
+import (
+	"errors"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"sort"
+)
+
+var errKeyNotFound = errors.New("key not found")
+
+// hashTableValue is a mutable table associating keys with values. Keys are
+// compared with equal?, or with eqv? or eq? for tables made that way.
+type hashTableValue struct {
+	same    func(a, b value) bool
+	deep    bool // whether same looks into pairs and vectors, as equal? does
+	buckets map[uint64][]*hashEntry
+	count   int
+	next    int // sequence number of the next entry added
+}
+
+type hashEntry struct {
+	key value
+	val value
+	seq int // order in which entries were added
+}
+
+// newHashTable returns an empty table comparing keys with same, which must
+// look into pairs and vectors only if deep is set.
+func newHashTable(same func(a, b value) bool, deep bool) *hashTableValue {
+	return &hashTableValue{same: same, deep: deep, buckets: make(map[uint64][]*hashEntry)}
+}
+
+func (_ *hashTableValue) valueType() {
+	// does nothing
+}
+
+func (v *hashTableValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case *hashTableValue:
+		return v == other, nil
+	default:
+		return false, nil
+	}
+}
+
+// lookup returns the entry for key, or nil if there is none.
+func (t *hashTableValue) lookup(key value) *hashEntry {
+	for _, e := range t.buckets[hashValue(key, t.deep)] {
+		if t.same(e.key, key) {
+			return e
+		}
+	}
+	return nil
+}
+
+func (t *hashTableValue) set(key, val value) {
+	if e := t.lookup(key); e != nil {
+		e.val = val
+		return
+	}
+
+	h := hashValue(key, t.deep)
+	t.buckets[h] = append(t.buckets[h], &hashEntry{key: key, val: val, seq: t.next})
+	t.count++
+	t.next++
+}
+
+func (t *hashTableValue) delete(key value) {
+	h := hashValue(key, t.deep)
+
+	bucket := t.buckets[h]
+	for i, e := range bucket {
+		if t.same(e.key, key) {
+			bucket = append(bucket[:i], bucket[i+1:]...)
+			t.count--
+			break
+		}
+	}
+
+	if len(bucket) == 0 {
+		delete(t.buckets, h)
+	} else {
+		t.buckets[h] = bucket
+	}
+}
+
+// entries returns the entries of the table in the order they were added.
+func (t *hashTableValue) entries() []*hashEntry {
+	res := make([]*hashEntry, 0, t.count)
+	for _, bucket := range t.buckets {
+		res = append(res, bucket...)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].seq < res[j].seq })
+	return res
+}
+
+var hashSeed = maphash.MakeSeed()
+
+// hashDepth limits how deeply nested pairs and vectors are hashed, so that
+// hashing terminates for cyclic structures.
+const hashDepth = 4
+
+// hashValue returns a hash of v that is the same for values that are equal?,
+// if deep is set, or eqv? otherwise. Values that are eq? are also eqv?, so
+// have the same hash.
+func hashValue(v value, deep bool) uint64 {
+	var h maphash.Hash
+	h.SetSeed(hashSeed)
+
+	writeHash(&h, v, deep, hashDepth)
+	return h.Sum64()
+}
+
+func writeHash(h *maphash.Hash, v value, deep bool, depth int) {
+	writeUint := func(n uint64) {
+		for i := 0; i < 8; i++ {
+			h.WriteByte(byte(n >> (8 * i)))
+		}
+	}
+
+	switch v := v.(type) {
+	case nullValue:
+		h.WriteByte(0)
+	case boolValue:
+		h.WriteByte(1)
+		if v.underlying {
+			h.WriteByte(1)
+		}
+	case numberValue:
+		h.WriteByte(2)
+		writeUint(uint64(v.underlying))
+	case bigValue:
+		h.WriteByte(3)
+		h.WriteString(v.underlying.String())
+	case ratValue:
+		h.WriteByte(4)
+		h.WriteString(v.underlying.String())
+	case floatValue:
+		h.WriteByte(5)
+		writeUint(math.Float64bits(v.underlying))
+	case stringValue:
+		h.WriteByte(6)
+		h.WriteString(v.underlying)
+	case charValue:
+		h.WriteByte(7)
+		writeUint(uint64(v.underlying))
+	case *symbolValue:
+		h.WriteByte(8)
+		h.WriteString(v.name)
+	case *pairValue:
+		h.WriteByte(9)
+		if !deep {
+			writeUint(uint64(reflect.ValueOf(v).Pointer()))
+		} else if depth > 0 {
+			writeHash(h, v.car, deep, depth-1)
+			writeHash(h, v.cdr, deep, depth-1)
+		}
+	case *vectorValue:
+		h.WriteByte(10)
+		if !deep {
+			writeUint(uint64(reflect.ValueOf(v).Pointer()))
+		} else if depth > 0 {
+			writeUint(uint64(len(v.elems)))
+			for i, e := range v.elems {
+				if i == hashDepth {
+					break
+				}
+				writeHash(h, e, deep, depth-1)
+			}
+		}
+	case *bytevectorValue:
+		h.WriteByte(11)
+		if !deep {
+			writeUint(uint64(reflect.ValueOf(v).Pointer()))
+		} else {
+			h.Write(v.bytes)
+		}
+	default:
+		// Other values, such as procedures, are only ever the same as
+		// themselves.
+		h.WriteByte(12)
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr {
+			writeUint(uint64(rv.Pointer()))
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package scheme
+
+import (
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestHashTables(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{src: `(make-hash-table)`, want: `#<hash-table 0>`},
+		{src: `(list (hash-table? (make-hash-table)) (hash-table? '()) (hash-table? #(1)))`, want: `(#t #f #f)`},
+		{
+			src: `
+				(define h (make-hash-table))
+				(hash-table-set! h "a" 1)
+				(hash-table-set! h 'b 2)
+				(hash-table-set! h 100000000000000000000 3)
+				(hash-table-set! h 1/2 4)
+				(hash-table-set! h #\c 5)
+				(list
+				  (hash-table-ref h (string #\a))
+				  (hash-table-ref h 'b)
+				  (hash-table-ref h (* 10000000000 10000000000))
+				  (hash-table-ref h (/ 2 4))
+				  (hash-table-ref h #\c)
+				  (hash-table-count h))`,
+			want: `(1 2 3 4 5 5)`,
+		},
+		{
+			src: `
+				(define h (make-hash-table equal?))
+				(hash-table-set! h (list 1 (vector 2 "x")) 'found)
+				(hash-table-ref h '(1 #(2 "x")))`,
+			want: `found`,
+		},
+		{
+			src: `
+				(define h (make-hash-table eqv?))
+				(define k (list 1 2))
+				(hash-table-set! h k 'k)
+				(hash-table-set! h "s" 's)
+				(list
+				  (hash-table-ref h k)
+				  (hash-table-ref/default h (list 1 2) 'none)
+				  (hash-table-ref/default h "s" 'none)
+				  (hash-table-ref/default h 1.5 'none))`,
+			want: `(k none s none)`,
+		},
+		{
+			src: `
+				(define h (make-hash-table eq?))
+				(define s "s")
+				(hash-table-set! h 'a 1)
+				(hash-table-set! h 2 2)
+				(hash-table-set! h s 3)
+				(list (hash-table-ref h 'a) (hash-table-ref h 2) (hash-table-ref h s) (hash-table-ref/default h "s" 'none))`,
+			want: `(1 2 3 none)`,
+		},
+		{
+			src: `
+				(define h (make-hash-table))
+				(hash-table-set! h 0.0 'zero)
+				(hash-table-set! h 1 'exact)
+				(list (hash-table-ref h 0.0) (hash-table-ref/default h -0.0 'negative) (hash-table-ref/default h 1.0 'inexact))`,
+			want: `(zero negative inexact)`,
+		},
+		{
+			src: `
+				(define h (make-hash-table eqv?))
+				(define e (make-hash-table eq?))
+				(define nan (/ 0.0 0.0))
+				(hash-table-set! h nan 1)
+				(hash-table-set! h nan 2)
+				(hash-table-set! e nan 1)
+				(hash-table-set! e nan 2)
+				(list (hash-table-ref h nan) (hash-table-count h) (hash-table-ref e nan) (hash-table-count e))`,
+			want: `(2 1 2 1)`,
+		},
+		{
+			src: `
+				(define h (make-hash-table))
+				(hash-table-set! h 'a 1)
+				(hash-table-set! h 'a 2)
+				(list (hash-table-ref h 'a) (hash-table-count h))`,
+			want: `(2 1)`,
+		},
+		{
+			src: `
+				(define h (make-hash-table))
+				(hash-table-set! h 'c 1)
+				(hash-table-set! h 'a 2)
+				(hash-table-set! h 'b 3)
+				(hash-table-delete! h 'a)
+				(hash-table-delete! h 'z)
+				(hash-table-set! h 'a 4)
+				(list (hash-table-keys h) (hash-table-values h) (hash-table-count h))`,
+			want: `((c b a) (1 3 4) 3)`,
+		},
+		{
+			src: `
+				(define h (make-hash-table))
+				(hash-table-set! h 'a 1)
+				(list (hash-table-contains? h 'a) (hash-table-contains? h 'b) (hash-table-keys (make-hash-table)))`,
+			want: `(#t #f ())`,
+		},
+		{
+			src: `
+				(define h (make-hash-table))
+				(hash-table-set! h 'a 1)
+				(hash-table-set! h 'b 2)
+				(define sum 0)
+				(hash-table-walk h (lambda (k v) (set! sum (+ sum v)) (hash-table-delete! h k)))
+				(list sum (hash-table-count h))`,
+			want: `(3 0)`,
+		},
+		{
+			src: `
+				(define h (make-hash-table))
+				(hash-table-set! h "n" 1)
+				(hash-table-update! h "n" (lambda (n) (+ n 1)))
+				(hash-table-update! h "m" (lambda (n) (+ n 1)) (lambda () 10))
+				(list (hash-table-ref h "n") (hash-table-ref h "m"))`,
+			want: `(2 11)`,
+		},
+		{src: `(hash-table-ref (make-hash-table) 'a (lambda () 'missing))`, want: `missing`},
+		{
+			src: `
+				(define h (make-hash-table))
+				(define p (list 1))
+				(set-cdr! p p)
+				(hash-table-set! h p 'cyclic)
+				(hash-table-ref h p)`,
+			want: `cyclic`,
+		},
+		{src: `(hash-table-ref (make-hash-table) 'a)`, wantErr: errKeyNotFound},
+		{src: `(hash-table-update! (make-hash-table) 'a (lambda (n) n))`, wantErr: errKeyNotFound},
+		{src: `(hash-table-ref '((a . 1)) 'a)`, wantErr: errInvalidArgumentType},
+		{src: `(make-hash-table string=?)`, wantErr: errInvalidArgumentType},
+		{src: `(hash-table-walk (make-hash-table) 1)`, wantErr: errInvalidArgumentType},
+		{src: `(hash-table-set! (make-hash-table) 'a)`, wantErr: errWrongNumberOfArguments},
+		{src: `(hash-table-count)`, wantErr: errWrongNumberOfArguments},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		in := NewInterpreter()
+		got, gotErr := in.Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Package scheme implements a small, non-standard Scheme interpreter that can
+// be embedded in Go programs.
+package scheme
+
+// ErrUnclosedExpression is returned by Eval when the source ends before every
+// expression has been closed. Callers reading interactive input can use it to
+// ask for more input rather than reporting an error.
+var ErrUnclosedExpression = errUnclosedExpression
+
+// Interpreter evaluates Scheme source within its own global environment.
+// Bindings made by one call to Eval are visible to later calls.
+type Interpreter struct {
+	global *frame
+}
+
+// NewInterpreter returns an interpreter whose global environment starts out
+// with the bindings of the standard library. The bindings are copied, so that
+// assigning to one with set! only affects this interpreter.
+func NewInterpreter() *Interpreter {
+	return &Interpreter{global: stdlib.copy()}
+}
+
+// Eval evaluates every expression in src and returns the value of the last
+// one.
+func (in *Interpreter) Eval(src string) (Value, error) {
+	return in.EvalSource("", src)
+}
+
+// EvalSource is like Eval, but errors report positions within src against the
+// given file name.
+func (in *Interpreter) EvalSource(file, src string) (Value, error) {
+	exprs, err := parseSource(file, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return evalSequence(exprs, in.global)
+}
+
+// EvalEach evaluates the expressions in src in turn, calling f with the value
+// of each, and stops at the first error. Nothing is evaluated if src cannot be
+// parsed.
+func (in *Interpreter) EvalEach(file, src string, f func(Value)) error {
+	exprs, err := parseSource(file, src)
+	if err != nil {
+		return err
+	}
+
+	for _, expr := range exprs {
+		v, err := eval(expr, in.global)
+		if err != nil {
+			return err
+		}
+
+		f(v)
+	}
+
+	return nil
+}
+
+// Define binds name to v in the interpreter's global environment.
+func (in *Interpreter) Define(name string, v Value) {
+	in.global.set(name, v)
+}
+
+// Lookup returns the value bound to name in the interpreter's global
+// environment, including bindings inherited from the standard library.
+func (in *Interpreter) Lookup(name string) (Value, error) {
+	return in.global.get(name)
+}
@@ -0,0 +1,331 @@
+package scheme
+
+import (
+	"reflect"
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestInterpreterEval(t *testing.T) {
+	cases := []struct {
+		src  string
+		want value
+	}{
+		{
+			src:  `(let ((x (+ 1 5)) (y 4)) (+ y x))`,
+			want: numberValue{10},
+		},
+		{
+			src:  `((lambda (x y) (+ x y)) 1 2)`,
+			want: numberValue{3},
+		},
+		{
+			src: `
+				(let ((add1 (lambda (x) (+ x 1)))
+				      (add2 (lambda (x) (+ x 2)))
+				      (x 5))
+				  (add1 (add2 (add1 x))))
+			`,
+			want: numberValue{9},
+		},
+		{
+			src:  `(if (= 1 2) (+1 2) (+ 3 4))`,
+			want: numberValue{7},
+		},
+		{
+			src: `
+				(let ((tri (lambda (x f)
+				             (if (= x 0)
+				                 0
+				                 (+ x (f (- x 1) f))))))
+				  (tri 100 tri))
+			`,
+			want: numberValue{5050},
+		},
+		{
+			src: `
+				; Sums its arguments.
+				(define (sum a b) #| a block
+				                     #| nested |# comment |#
+				  (+ a #;(* 100 b) b)) ; trailing comment
+				(sum 1 2)
+			`,
+			want: numberValue{3},
+		},
+		{
+			src: `
+				(let ((y (lambda (f)
+				           ((lambda (procedure)
+				              (f (lambda (arg) ((procedure procedure) arg))))
+				            (lambda (procedure)
+				              (f (lambda (arg) ((procedure procedure) arg)))))))
+				      (fib-pre (lambda (f)
+				                 (lambda (n)
+				                   (if (= n 0)
+				                       0
+				                       (if (= n 1)
+				                           1
+				                           (+ (f (- n 1)) (f (- n 2)))))))))
+				  (let ((fib (y fib-pre)))
+				    (fib 10)))
+			`,
+			want: numberValue{55},
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, err := NewInterpreter().Eval(c.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("value:\ngot:  %v\nwant: %v", got, c.want)
+		}
+	}
+}
+
+func TestInterpreterBindings(t *testing.T) {
+	in := NewInterpreter()
+
+	in.Define("x", Number(20))
+
+	if _, err := in.Eval(`(define (add-x y) (+ x y))`); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := in.Eval(`(add-x 22)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, Number(42)) {
+		t.Errorf("value:\ngot:  %v\nwant: %v", got, Number(42))
+	}
+
+	if _, err := in.Lookup("add-x"); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if _, err := in.Lookup("car"); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if _, err := in.Lookup("missing"); errs.Root(err) != errBindingNotFound {
+		t.Errorf("error:\ngot:  %v\nwant: %v", errs.Root(err), errBindingNotFound)
+	}
+
+	// Bindings are private to each interpreter.
+	if _, err := NewInterpreter().Lookup("add-x"); errs.Root(err) != errBindingNotFound {
+		t.Errorf("error:\ngot:  %v\nwant: %v", errs.Root(err), errBindingNotFound)
+	}
+
+	if _, err := in.Eval(`(+ 1`); errs.Root(err) != ErrUnclosedExpression {
+		t.Errorf("error:\ngot:  %v\nwant: %v", errs.Root(err), ErrUnclosedExpression)
+	}
+}
+
+func TestInterpreterIsolation(t *testing.T) {
+	a, b := NewInterpreter(), NewInterpreter()
+
+	if _, err := a.Eval(`(set! car (lambda (x) 'hijacked))`); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := a.Eval(`(car '(1 2))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `hijacked`; Repr(got) != want {
+		t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), want)
+	}
+
+	got, err = b.Eval(`(car '(1 2))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `1`; Repr(got) != want {
+		t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), want)
+	}
+
+	if _, err := NewInterpreter().Eval(`(set! undefined-name 1)`); errs.Root(err) != errBindingNotFound {
+		t.Errorf("error:\ngot:  %v\nwant: %v", errs.Root(err), errBindingNotFound)
+	}
+}
+
+func TestInterpreterEvalEach(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    []string
+		wantErr error
+	}{
+		{src: ``, want: nil},
+		{src: `1 "foo" (+ 1 2)`, want: []string{`1`, `"foo"`, `3`}},
+		{src: `(define a 1) a (car a) 2`, want: []string{`()`, `1`}, wantErr: errInvalidArgumentType},
+		{src: `1 (+ 1`, want: nil, wantErr: ErrUnclosedExpression},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		var got []string
+		gotErr := NewInterpreter().EvalEach("", c.src, func(v Value) {
+			got = append(got, Repr(v))
+		})
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("values:\ngot:  %v\nwant: %v", got, c.want)
+		}
+	}
+}
+
+func TestInterpreterTailCalls(t *testing.T) {
+	cases := []struct {
+		src  string
+		want value
+	}{
+		{
+			src: `
+				(define (count n acc)
+				  (if (= n 0)
+				      acc
+				      (count (- n 1) (+ acc 1))))
+				(count 1000000 0)
+			`,
+			want: numberValue{1000000},
+		},
+		{
+			src: `
+				(define (even? n) (if (= n 0) true (odd? (- n 1))))
+				(define (odd? n) (if (= n 0) false (even? (- n 1))))
+				(even? 100001)
+			`,
+			want: boolValue{false},
+		},
+		{
+			src: `
+				(define (down n) (or (= n 0) (and n (down (- n 1)))))
+				(down 100000)
+			`,
+			want: boolValue{true},
+		},
+		{
+			src: `
+				(define (loop n)
+				  (begin
+				    n
+				    (let ((m (- n 1)))
+				      (if (= m 0)
+				          "done"
+				          (loop m)))))
+				(loop 100000)
+			`,
+			want: newString("done"),
+		},
+		{
+			src: `
+				(let ((tri (lambda (x acc f)
+				             (if (= x 0)
+				                 acc
+				                 (f (- x 1) (+ acc x) f)))))
+				  (tri 100000 0 tri))
+			`,
+			want: numberValue{5000050000},
+		},
+		{
+			src: `
+				(define (loop n) (cond ((= n 0) 0) ((- n 1) => loop)))
+				(loop 1000000)
+			`,
+			want: numberValue{0},
+		},
+		{
+			src: `
+				(define (loop n)
+				  (case (= n 0)
+				    ((#t) 'done)
+				    (else => (lambda (_) (loop (- n 1))))))
+				(loop 1000000)
+			`,
+			want: &symbolValue{name: "done"},
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, err := NewInterpreter().Eval(c.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("value:\ngot:  %v\nwant: %v", got, c.want)
+		}
+	}
+}
+
+func TestInterpreterErrorLocation(t *testing.T) {
+	cases := []struct {
+		src     string
+		wantErr error
+		wantMsg string
+	}{
+		{
+			src:     "(define x 1)\n(+ x\n   (undefined 2))",
+			wantErr: errBindingNotFound,
+			wantMsg: `test.scm:3:5: undefined: environment does not contain binding: "undefined"`,
+		},
+		{
+			src:     "(define (f g) (g 1))\n(f 2)",
+			wantErr: errApplicationOnNonProc,
+			wantMsg: `test.scm:1:15: (g 1): application operator must evaluate to proc`,
+		},
+		{
+			src:     "(cond\n  (else 1)\n  (#t 2))",
+			wantErr: errInvalidClause,
+			wantMsg: `test.scm:2:3: (else 1): cond: invalid clause`,
+		},
+		{
+			src:     "((lambda (x) x))",
+			wantErr: errWrongNumberOfArguments,
+			wantMsg: `test.scm:1:1: ((lambda (x) x)): application with wrong number of arguments`,
+		},
+		{
+			src:     `(if)`,
+			wantErr: errInvalidCompoundExpression,
+			wantMsg: `test.scm:1:1: (if): invalid compound expression`,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		_, err := NewInterpreter().EvalSource("test.scm", c.src)
+
+		if errs.Root(err) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", err, c.wantErr)
+			continue
+		}
+
+		if err.Error() != c.wantMsg {
+			t.Errorf("message:\ngot:  %v\nwant: %v", err, c.wantMsg)
+		}
+
+		located, ok := err.(*Error)
+		if !ok {
+			t.Errorf("error should be *Error: %T", err)
+			continue
+		}
+
+		if located.File() != "test.scm" {
+			t.Errorf("file:\ngot:  %v\nwant: %v", located.File(), "test.scm")
+		}
+	}
+}
@@ -0,0 +1,607 @@
+package scheme
+
+import (
+	"errors"
+	"strconv"
+	"sync/atomic"
+
+	"scgeme/errs"
+)
+
+var (
+	errInvalidSyntaxRules = errors.New("invalid syntax-rules")
+	errNoMatchingRule     = errors.New("no syntax rule matches")
+	errEllipsisDepth      = errors.New("pattern variable used at wrong ellipsis depth")
+)
+
+// macro is a value bound to a keyword, whose uses are expanded into other
+// expressions before they are evaluated.
+type macro interface {
+	value
+	expand(expr expression, env *frame) (expression, error)
+}
+
+// macroValue is a macro defined with syntax-rules. A use of the macro is
+// expanded by matching it against the pattern of each rule in turn, and
+// instantiating the template of the first rule that matches.
+//
+// Expansion is hygienic: identifiers inserted by a template are renamed, so
+// that they neither capture nor are captured by identifiers of the macro use.
+// A renamed identifier that the expansion does not bind refers to its binding
+// in env, the environment in which the macro was defined.
+type macroValue struct {
+	name     string
+	ellipsis string // empty if the ellipsis is declared a literal
+	literals map[string]bool
+	rules    []syntaxRule
+	env      *frame
+}
+
+type syntaxRule struct {
+	pattern  *compoundExpression
+	template expression
+}
+
+func (_ *macroValue) valueType() {
+	// does nothing
+}
+
+func (v *macroValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case *macroValue:
+		return v == other, nil
+	default:
+		return false, nil
+	}
+}
+
+// alias records, for an identifier renamed by macro expansion, the identifier
+// it was copied from and the environment in which to resolve it.
+type alias struct {
+	original *tokenExpression
+	env      *frame
+}
+
+// name returns the identifier as written in the source, before any renaming.
+func (e *tokenExpression) name() string {
+	for e.alias != nil {
+		e = e.alias.original
+	}
+	return e.token
+}
+
+// renames counts identifiers renamed by macro expansion, so that each one
+// is given a distinct token.
+var renames int64
+
+// resolve returns the value bound to the identifier expr in env. An identifier
+// renamed by macro expansion that is not bound under its new name is resolved
+// as the original identifier in the environment of the macro.
+func resolve(expr *tokenExpression, env *frame) (value, error) {
+	if v, ok := lookupIdentifier(expr, env); ok {
+		if v == unassigned {
+			return nil, errs.WrapAfterf(errUnassigned, "%q", expr.name())
+		}
+		return v, nil
+	}
+
+	return nil, errs.WrapAfterf(errBindingNotFound, "%q", expr.name())
+}
+
+func lookupIdentifier(expr *tokenExpression, env *frame) (value, bool) {
+	for {
+		if v, ok := env.lookup(expr.token); ok {
+			return v, true
+		}
+
+		if expr.alias == nil {
+			return nil, false
+		}
+
+		expr, env = expr.alias.original, expr.alias.env
+	}
+}
+
+// sameBinding reports whether the identifier a in env a, and the identifier
+// b in env b, refer to the same binding, or are both unbound and have the
+// same name.
+func sameBinding(a *tokenExpression, aEnv *frame, b *tokenExpression, bEnv *frame) bool {
+	af, ak := bindingOf(a, aEnv)
+	bf, bk := bindingOf(b, bEnv)
+	return af == bf && ak == bk
+}
+
+// bindingOf returns the frame binding the identifier expr in env, as resolve
+// would find it, and the name it is bound under there. The frame is nil if
+// expr is unbound.
+func bindingOf(expr *tokenExpression, env *frame) (*frame, string) {
+	for {
+		if f := env.owner(expr.token); f != nil || expr.alias == nil {
+			return f, expr.token
+		}
+
+		expr, env = expr.alias.original, expr.alias.env
+	}
+}
+
+// assignIdentifier rebinds the identifier expr to v, in the frame where
+// resolve would find it.
+func assignIdentifier(expr *tokenExpression, v value, env *frame) error {
+	for {
+		if _, ok := env.lookup(expr.token); ok || expr.alias == nil {
+			return env.assign(expr.token, v)
+		}
+
+		expr, env = expr.alias.original, expr.alias.env
+	}
+}
+
+// macroUse returns the macro named by the keyword of expr, if expr is a use of
+// a macro bound in env.
+func macroUse(expr expression, env *frame) (macro, bool) {
+	c, ok := expr.(*compoundExpression)
+	if !ok || len(c.children) == 0 {
+		return nil, false
+	}
+
+	head, ok := c.children[0].(*tokenExpression)
+	if !ok {
+		return nil, false
+	}
+
+	v, _ := lookupIdentifier(head, env)
+	m, ok := v.(macro)
+	return m, ok
+}
+
+// cachedExpansion is the expansion of a macro use by the macro its keyword was
+// bound to at the time.
+type cachedExpansion struct {
+	macro macro
+	expr  expression
+}
+
+// expandUse returns the expansion of expr, a use of the macro m in env. A use
+// is expanded once, and its expansion reused for as long as its keyword is
+// bound to the same macro, so that transformers are not run again each time
+// the use is evaluated.
+func expandUse(expr expression, m macro, env *frame) (expression, error) {
+	c := expr.(*compoundExpression)
+	if x := c.expansion; x != nil && x.macro == m {
+		return x.expr, nil
+	}
+
+	res, err := m.expand(expr, env)
+	if err != nil {
+		return nil, err
+	}
+
+	c.expansion = &cachedExpansion{macro: m, expr: res}
+	return res, nil
+}
+
+// newMacro creates the macro described by spec, which must be of the form
+// (syntax-rules [ellipsis] (literal ...) (pattern template) ...).
+func newMacro(name string, spec expression, env *frame) (*macroValue, error) {
+	c, ok := spec.(*compoundExpression)
+	if !ok || len(c.children) < 2 {
+		return nil, errs.WrapAfterf(errInvalidSyntaxRules, "%s", snippet(spec))
+	}
+
+	if head, ok := c.children[0].(*tokenExpression); !ok || head.name() != "syntax-rules" {
+		return nil, errs.WrapAfterf(errInvalidSyntaxRules, "want syntax-rules, got %s", snippet(spec))
+	}
+
+	m := &macroValue{name: name, ellipsis: "...", literals: make(map[string]bool), env: env}
+
+	rest := c.children[1:]
+	if t, ok := rest[0].(*tokenExpression); ok {
+		m.ellipsis = t.name()
+		rest = rest[1:]
+	}
+
+	if len(rest) == 0 || !isCompoundExpression(rest[0]) {
+		return nil, errs.WrapAfterf(errInvalidSyntaxRules, "missing literals list")
+	}
+
+	for _, l := range mustExpressionChildren(rest[0]) {
+		t, ok := l.(*tokenExpression)
+		if !ok {
+			return nil, errs.WrapAfterf(errInvalidSyntaxRules, "literal %s is not an identifier", snippet(l))
+		}
+
+		m.literals[t.token] = true
+		if t.name() == m.ellipsis {
+			m.ellipsis = ""
+		}
+	}
+
+	for _, r := range rest[1:] {
+		rc, ok := r.(*compoundExpression)
+		if !ok || len(rc.children) != 2 {
+			return nil, errs.WrapAfterf(errInvalidSyntaxRules, "invalid rule %s", snippet(r))
+		}
+
+		pattern, ok := rc.children[0].(*compoundExpression)
+		if !ok || len(pattern.children) == 0 {
+			return nil, errs.WrapAfterf(errInvalidSyntaxRules, "invalid pattern %s", snippet(rc.children[0]))
+		}
+
+		m.rules = append(m.rules, syntaxRule{pattern: pattern, template: rc.children[1]})
+	}
+
+	return m, nil
+}
+
+// expand returns the expansion of expr, a use of the macro in env.
+func (m *macroValue) expand(expr expression, env *frame) (expression, error) {
+	form := expr.(*compoundExpression)
+
+	for _, r := range m.rules {
+		// The keyword position of the pattern is ignored.
+		b := make(bindings)
+		pattern := afterKeyword(r.pattern.children)
+		input := afterKeyword(form.children)
+
+		if !m.match(pattern, input, env, b) {
+			continue
+		}
+
+		x := &expansion{
+			macro:    m,
+			ellipsis: m.ellipsis,
+			renamed:  make(map[string]*tokenExpression),
+			span:     form.span,
+		}
+		return x.instantiate(r.template, b)
+	}
+
+	return nil, errs.WrapAfterf(errNoMatchingRule, "%s", m.name)
+}
+
+// afterKeyword returns the part of a macro use or pattern that follows its
+// keyword. That is a list, unless the keyword is followed by a dot, as in the
+// pattern (_ . args), when it is the final cdr itself.
+func afterKeyword(children []expression) expression {
+	rest := children[1:]
+	if len(rest) == 2 && isDot(rest[0]) {
+		return rest[1]
+	}
+	return &compoundExpression{children: rest}
+}
+
+// binding is what a pattern variable matched. Variables that occur under n
+// ellipses in the pattern are bound to n levels of nested sequences of
+// matches.
+type binding struct {
+	expr  expression
+	items []bindings
+}
+
+type bindings map[string]*binding
+
+func (m *macroValue) isEllipsis(expr expression) bool {
+	t, ok := expr.(*tokenExpression)
+	return ok && m.ellipsis != "" && t.name() == m.ellipsis
+}
+
+// isVariable reports whether the pattern element t is a pattern variable.
+func (m *macroValue) isVariable(t *tokenExpression) bool {
+	if m.literals[t.token] || t.name() == "_" || t.name() == m.ellipsis || isDot(t) {
+		return false
+	}
+
+	k, _ := classifyToken(t)
+	return k == exprDereference
+}
+
+// match reports whether the input, from a use of the macro in env, matches the
+// pattern, recording the matches of pattern variables in b. A literal matches
+// an identifier that refers to the same binding as the literal does where the
+// macro was defined.
+func (m *macroValue) match(pattern, input expression, env *frame, b bindings) bool {
+	switch p := pattern.(type) {
+	case *tokenExpression:
+		in, isToken := input.(*tokenExpression)
+
+		switch {
+		case m.literals[p.token]:
+			return isToken && sameBinding(in, env, p, m.env)
+		case p.name() == "_":
+			return true
+		case m.isVariable(p):
+			b[p.token] = &binding{expr: input}
+			return true
+		default:
+			return isToken && in.alias == nil && in.token == p.token
+		}
+
+	case *compoundExpression:
+		in, ok := input.(*compoundExpression)
+		if !ok || in.vector != p.vector {
+			return false
+		}
+
+		pats, ptail, err := splitDotted(p.children)
+		if err != nil {
+			return false
+		}
+
+		elems, tail, err := splitDotted(in.children)
+		if err != nil {
+			return false
+		}
+
+		return m.matchList(pats, ptail, elems, tail, env, b)
+
+	default:
+		return false
+	}
+}
+
+// matchList matches the elements of an input list, and its final cdr if it is
+// improper, against the elements and final cdr of a list pattern.
+func (m *macroValue) matchList(pats []expression, ptail expression, elems []expression, tail expression, env *frame, b bindings) bool {
+	var (
+		before = pats
+		repeat expression
+		after  []expression
+	)
+
+	for i := 1; i < len(pats); i++ {
+		if m.isEllipsis(pats[i]) {
+			before, repeat, after = pats[:i-1], pats[i-1], pats[i+1:]
+			break
+		}
+	}
+
+	n := len(elems) - len(before) - len(after)
+	if n < 0 || (repeat == nil && n > 0 && ptail == nil) {
+		return false
+	}
+
+	for i, p := range before {
+		if !m.match(p, elems[i], env, b) {
+			return false
+		}
+	}
+
+	if repeat != nil {
+		var items []bindings
+		for _, e := range elems[len(before) : len(before)+n] {
+			ib := make(bindings)
+			if !m.match(repeat, e, env, ib) {
+				return false
+			}
+			items = append(items, ib)
+		}
+
+		for _, v := range m.variables(repeat) {
+			b[v] = &binding{items: items}
+		}
+
+		for i, p := range after {
+			if !m.match(p, elems[len(before)+n+i], env, b) {
+				return false
+			}
+		}
+
+		n = 0
+	}
+
+	rest := elems[len(elems)-n:]
+
+	if ptail == nil {
+		return tail == nil
+	}
+
+	// The pattern tail matches the remaining elements and tail of the input.
+	if len(rest) == 0 && tail != nil {
+		return m.match(ptail, tail, env, b)
+	}
+
+	children := rest
+	if tail != nil {
+		children = append(append([]expression(nil), rest...), &tokenExpression{token: "."}, tail)
+	}
+
+	return m.match(ptail, &compoundExpression{children: children}, env, b)
+}
+
+// variables returns the pattern variables occurring in pattern.
+func (m *macroValue) variables(pattern expression) []string {
+	switch p := pattern.(type) {
+	case *tokenExpression:
+		if m.isVariable(p) {
+			return []string{p.token}
+		}
+		return nil
+	case *compoundExpression:
+		var res []string
+		for _, c := range p.children {
+			res = append(res, m.variables(c)...)
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+// expansion is the state of instantiating a template for one use of a macro.
+type expansion struct {
+	macro    *macroValue
+	ellipsis string
+	renamed  map[string]*tokenExpression
+	span     span // location of the macro use
+}
+
+func (x *expansion) isEllipsis(expr expression) bool {
+	t, ok := expr.(*tokenExpression)
+	return ok && x.ellipsis != "" && t.name() == x.ellipsis
+}
+
+// instantiate replaces the pattern variables of tmpl with their matches in b,
+// and renames the identifiers that tmpl inserts.
+func (x *expansion) instantiate(tmpl expression, b bindings) (expression, error) {
+	switch t := tmpl.(type) {
+	case *tokenExpression:
+		if v, ok := b[t.token]; ok {
+			if v.expr == nil {
+				return nil, errs.WrapAfterf(errEllipsisDepth, "%s", t.name())
+			}
+			return v.expr, nil
+		}
+
+		if k, _ := classifyToken(t); k != exprDereference || isDot(t) {
+			return t, nil
+		}
+
+		if x.isEllipsis(t) {
+			return nil, errs.WrapAfterf(errInvalidSyntaxRules, "misplaced %s", t.name())
+		}
+
+		return x.rename(t), nil
+
+	case *compoundExpression:
+		// (... template) escapes the ellipsis within template.
+		if len(t.children) == 2 && x.isEllipsis(t.children[0]) {
+			saved := x.ellipsis
+			x.ellipsis = ""
+			defer func() { x.ellipsis = saved }()
+
+			return x.instantiate(t.children[1], b)
+		}
+
+		var children []expression
+		for i := 0; i < len(t.children); i++ {
+			depth := 0
+			for i+depth+1 < len(t.children) && x.isEllipsis(t.children[i+depth+1]) {
+				depth++
+			}
+
+			if depth == 0 {
+				c, err := x.instantiate(t.children[i], b)
+				if err != nil {
+					return nil, err
+				}
+
+				children = append(children, c)
+				continue
+			}
+
+			cs, err := x.repeat(t.children[i], b, depth)
+			if err != nil {
+				return nil, err
+			}
+
+			children = append(children, cs...)
+			i += depth
+		}
+
+		// A template (a . rest) whose rest is replaced by a list produces
+		// the list (a rest...).
+		if n := len(children); n >= 2 && isDot(children[n-2]) {
+			if last, ok := children[n-1].(*compoundExpression); ok {
+				children = append(children[:n-2], last.children...)
+			}
+		}
+
+		return &compoundExpression{children: children, span: x.span, vector: t.vector}, nil
+
+	default:
+		return nil, errInvalidExpressionType
+	}
+}
+
+// repeat instantiates tmpl, which is followed by depth ellipses, once for each
+// match of the sequence variables it contains.
+func (x *expansion) repeat(tmpl expression, b bindings, depth int) ([]expression, error) {
+	var vars []string
+	n := -1
+
+	for _, v := range x.identifiers(tmpl) {
+		bv, ok := b[v]
+		if !ok || bv.expr != nil {
+			continue
+		}
+
+		if n >= 0 && len(bv.items) != n {
+			return nil, errs.WrapAfterf(errEllipsisDepth, "sequences of different lengths")
+		}
+
+		n = len(bv.items)
+		vars = append(vars, v)
+	}
+
+	if n < 0 {
+		return nil, errs.WrapAfterf(errEllipsisDepth, "no sequence variable in %s", snippet(tmpl))
+	}
+
+	var res []expression
+	for i := 0; i < n; i++ {
+		ib := make(bindings, len(b))
+		for k, v := range b {
+			ib[k] = v
+		}
+		for _, v := range vars {
+			for k, item := range b[v].items[i] {
+				ib[k] = item
+			}
+		}
+
+		if depth > 1 {
+			cs, err := x.repeat(tmpl, ib, depth-1)
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, cs...)
+			continue
+		}
+
+		c, err := x.instantiate(tmpl, ib)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, c)
+	}
+
+	return res, nil
+}
+
+// identifiers returns the tokens of the identifiers occurring in tmpl.
+func (x *expansion) identifiers(tmpl expression) []string {
+	switch t := tmpl.(type) {
+	case *tokenExpression:
+		return []string{t.token}
+	case *compoundExpression:
+		var res []string
+		for _, c := range t.children {
+			res = append(res, x.identifiers(c)...)
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+// rename returns the identifier that t is renamed to in this expansion. Every
+// occurrence of the same identifier is renamed alike.
+func (x *expansion) rename(t *tokenExpression) *tokenExpression {
+	if r, ok := x.renamed[t.token]; ok {
+		return r
+	}
+
+	// A space cannot occur in an identifier read from source, so the new
+	// token cannot clash with one.
+	n := atomic.AddInt64(&renames, 1)
+	r := &tokenExpression{
+		token: t.name() + " " + strconv.FormatInt(n, 10),
+		span:  x.span,
+		alias: &alias{original: t, env: x.macro.env},
+	}
+
+	x.renamed[t.token] = r
+	return r
+}
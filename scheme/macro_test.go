@@ -0,0 +1,205 @@
+package scheme
+
+import (
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestSyntaxRules(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{
+			src: `
+				(define-syntax my-unless
+				  (syntax-rules ()
+				    ((_ c body ...) (if c #f (begin body ...)))))
+				(list (my-unless #f 1 2) (my-unless #t 1 2))`,
+			want: `(2 #f)`,
+		},
+		{
+			src: `
+				(define-syntax my-let
+				  (syntax-rules ()
+				    ((_ ((n v) ...) body ...) ((lambda (n ...) body ...) v ...))))
+				(my-let ((a 1) (b 2)) (+ a b))`,
+			want: `3`,
+		},
+		{
+			src: `
+				(define-syntax my-or
+				  (syntax-rules ()
+				    ((_) #f)
+				    ((_ e) e)
+				    ((_ e r ...) (let ((t e)) (if t t (my-or r ...))))))
+				(let ((t #t)) (my-or #f t))`,
+			want: `#t`,
+		},
+		{
+			src: `
+				(define (helper) 'outer)
+				(define-syntax call-helper
+				  (syntax-rules ()
+				    ((_) (helper))))
+				(let ((helper (lambda () 'inner))) (call-helper))`,
+			want: `outer`,
+		},
+		{
+			src: `
+				(define-syntax arrow
+				  (syntax-rules (=>)
+				    ((_ a => b) (list a b))
+				    ((_ a b) 'no-arrow)))
+				(list (arrow 1 => 2) (arrow 1 2))`,
+			want: `((1 2) no-arrow)`,
+		},
+		{
+			src: `
+				(define-syntax m
+				  (syntax-rules ()
+				    ((_ . a) (quote a))))
+				(list (m 1 2) (m) (m . 3))`,
+			want: `((1 2) () 3)`,
+		},
+		{
+			src: `
+				(define-syntax my-list
+				  (syntax-rules ()
+				    ((_ . (a ...)) (list a ...))))
+				(my-list 1 2 3)`,
+			want: `(1 2 3)`,
+		},
+		{
+			src: `
+				(define-syntax my-cond
+				  (syntax-rules (else)
+				    ((_ (else e)) e)
+				    ((_ (c e)) (if c e 'none))))
+				(list
+				  (my-cond (else 1))
+				  (let ((else #f)) (my-cond (else 2))))`,
+			want: `(1 none)`,
+		},
+		{
+			src: `
+				(define-syntax arrow
+				  (syntax-rules (=>)
+				    ((_ a => b) (list a b))
+				    ((_ a b c) 'no-arrow)))
+				(define-syntax use-arrow
+				  (syntax-rules ()
+				    ((_ x) (arrow x => x))))
+				(list (use-arrow 1) (let ((=> 0)) (arrow 1 => 2)))`,
+			want: `((1 1) no-arrow)`,
+		},
+		{
+			src: `
+				(define-syntax flatten
+				  (syntax-rules ()
+				    ((_ (a b ...) ...) '(a ... b ... ...))))
+				(flatten (1 2 3) (4 5))`,
+			want: `(1 4 2 3 5)`,
+		},
+		{
+			src: `
+				(define-syntax rest
+				  (syntax-rules ()
+				    ((_ a . r) 'r)))
+				(rest 1 2 3)`,
+			want: `(2 3)`,
+		},
+		{
+			src: `
+				(define-syntax last
+				  (syntax-rules ()
+				    ((_ a ... z) 'z)))
+				(last 1 2 3)`,
+			want: `3`,
+		},
+		{
+			src: `
+				(define-syntax my-list
+				  (syntax-rules ::: ()
+				    ((_ x :::) (list x :::))))
+				(my-list 1 2 3)`,
+			want: `(1 2 3)`,
+		},
+		{
+			src: `
+				(define-syntax ellipsis
+				  (syntax-rules ()
+				    ((_ x) '(x (... ...)))))
+				(ellipsis 1)`,
+			want: `(1 ...)`,
+		},
+		{
+			src: `
+				(define-syntax def-const
+				  (syntax-rules ()
+				    ((_ name v) (define-syntax name (syntax-rules () ((_) v))))))
+				(def-const five 5)
+				(five)`,
+			want: `5`,
+		},
+		{
+			src:  `(let-syntax ((inc (syntax-rules () ((_ x) (+ x 1))))) (inc 2))`,
+			want: `3`,
+		},
+		{
+			src: `
+				(letrec-syntax
+				    ((ev? (syntax-rules () ((_) #t) ((_ x . r) (od? . r))))
+				     (od? (syntax-rules () ((_) #f) ((_ x . r) (ev? . r)))))
+				  (list (ev? 1 2 3) (ev? 1 2)))`,
+			want: `(#f #t)`,
+		},
+		{
+			src: `
+				(define-syntax nothing (syntax-rules () ((_) 1)))
+				nothing`,
+			want: `#<syntax nothing>`,
+		},
+		{
+			src: `
+				(define-syntax one (syntax-rules () ((_) 1)))
+				(one 2)`,
+			wantErr: errNoMatchingRule,
+		},
+		{
+			src: `
+				(define-syntax bad (syntax-rules () ((_ x ...) x)))
+				(bad 1)`,
+			wantErr: errEllipsisDepth,
+		},
+		{
+			src:     `(define-syntax bad (lambda (x) x))`,
+			wantErr: errInvalidSyntaxRules,
+		},
+		{
+			src:     `(define-syntax bad (syntax-rules () (_ 1)))`,
+			wantErr: errInvalidSyntaxRules,
+		},
+		{
+			src:     `(let-syntax ((inc (syntax-rules () ((_ x) (+ x 1))))) 1) (inc 2)`,
+			wantErr: errBindingNotFound,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := NewInterpreter().Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package scheme
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"scgeme/errs"
+)
+
+var errUnsupportedFuncType = errors.New("unsupported function type")
+
+var (
+	valueReflectType = reflect.TypeOf((*Value)(nil)).Elem()
+	errorReflectType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// DefineFunc binds name to a procedure that calls the Go function fn.
+//
+// Arguments are converted from Scheme values to the types of fn's parameters,
+// and fn's result is converted back. Supported types are integers, floats,
+// strings, booleans, slices of supported types (as proper lists), and Value,
+// which is passed through unconverted. Variadic functions accept any number of
+// trailing arguments. fn may return nothing, a single value, an error, or a
+// value and an error.
+func (in *Interpreter) DefineFunc(name string, fn interface{}) error {
+	v, err := newNativeProc(name, fn)
+	if err != nil {
+		return err
+	}
+
+	in.Define(name, v)
+	return nil
+}
+
+func newNativeProc(name string, fn interface{}) (*builtinValue, error) {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+
+	if ft.Kind() != reflect.Func {
+		return nil, errs.WrapAfterf(errUnsupportedFuncType, "%s: not a function", name)
+	}
+
+	for i := 0; i < ft.NumIn(); i++ {
+		t := ft.In(i)
+		if ft.IsVariadic() && i == ft.NumIn()-1 {
+			t = t.Elem()
+		}
+
+		if !convertibleType(t, false) {
+			return nil, errs.WrapAfterf(errUnsupportedFuncType, "%s: parameter %d has type %s", name, i+1, t)
+		}
+	}
+
+	switch ft.NumOut() {
+	case 0:
+	case 1:
+		if t := ft.Out(0); t != errorReflectType && !convertibleType(t, true) {
+			return nil, errs.WrapAfterf(errUnsupportedFuncType, "%s: result has type %s", name, t)
+		}
+	case 2:
+		if t := ft.Out(0); !convertibleType(t, true) {
+			return nil, errs.WrapAfterf(errUnsupportedFuncType, "%s: result has type %s", name, t)
+		}
+		if ft.Out(1) != errorReflectType {
+			return nil, errs.WrapAfterf(errUnsupportedFuncType, "%s: second result must be error", name)
+		}
+	default:
+		return nil, errs.WrapAfterf(errUnsupportedFuncType, "%s: too many results", name)
+	}
+
+	call := func(args []value) (value, error) {
+		in, err := nativeArgs(ft, args)
+		if err != nil {
+			return nil, errs.Wrap(err, name)
+		}
+
+		out := fv.Call(in)
+
+		if len(out) > 0 && out[len(out)-1].Type() == errorReflectType {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return nil, errs.Wrap(err, name)
+			}
+			out = out[:len(out)-1]
+		}
+
+		if len(out) == 0 {
+			return nullValue{}, nil
+		}
+
+		return toValue(out[0]), nil
+	}
+
+	return &builtinValue{name: name, fn: call}, nil
+}
+
+// nativeArgs converts args to the parameter types of the function type ft,
+// checking arity first.
+func nativeArgs(ft reflect.Type, args []value) ([]reflect.Value, error) {
+	fixed := ft.NumIn()
+	if ft.IsVariadic() {
+		fixed--
+	}
+
+	if len(args) < fixed || (len(args) > fixed && !ft.IsVariadic()) {
+		if ft.IsVariadic() {
+			return nil, errs.WrapAfterf(errWrongNumberOfArguments, "want at least %d, got %d", fixed, len(args))
+		}
+		return nil, errs.WrapAfterf(errWrongNumberOfArguments, "want %d, got %d", fixed, len(args))
+	}
+
+	res := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		var t reflect.Type
+		if i < fixed {
+			t = ft.In(i)
+		} else {
+			t = ft.In(fixed).Elem()
+		}
+
+		rv, err := fromValue(arg, t)
+		if err != nil {
+			return nil, errs.Wrapf(err, "argument %d", i+1)
+		}
+		res[i] = rv
+	}
+
+	return res, nil
+}
+
+// convertibleType reports whether values of type t can be converted from
+// Scheme values, when used as a parameter, or to them, when used as a result.
+func convertibleType(t reflect.Type, result bool) bool {
+	if t == valueReflectType || (!result && valueReflectType.AssignableTo(t)) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.String, reflect.Bool:
+		return true
+	case reflect.Slice:
+		return convertibleType(t.Elem(), result)
+	default:
+		return false
+	}
+}
+
+// fromValue converts a Scheme value to a Go value of type t.
+func fromValue(v value, t reflect.Type) (reflect.Value, error) {
+	if valueReflectType.AssignableTo(t) {
+		res := reflect.New(t).Elem()
+		res.Set(reflect.ValueOf(&v).Elem())
+		return res, nil
+	}
+
+	mismatch := func() error {
+		return errs.WrapAfterf(errInvalidArgumentType, "want %s, got %s", t, typeName(v))
+	}
+
+	res := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := integerArg(v, t)
+		if err != nil {
+			return res, err
+		}
+		if !n.IsInt64() || res.OverflowInt(n.Int64()) {
+			return res, errs.WrapAfterf(errInvalidArgumentType, "%s overflows %s", n, t)
+		}
+		res.SetInt(n.Int64())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := integerArg(v, t)
+		if err != nil {
+			return res, err
+		}
+		if !n.IsUint64() || res.OverflowUint(n.Uint64()) {
+			return res, errs.WrapAfterf(errInvalidArgumentType, "%s overflows %s", n, t)
+		}
+		res.SetUint(n.Uint64())
+
+	case reflect.Float32, reflect.Float64:
+		if !isNumber(v) {
+			return res, mismatch()
+		}
+		res.SetFloat(toFloat(v))
+
+	case reflect.String:
+		s, ok := v.(stringValue)
+		if !ok {
+			return res, mismatch()
+		}
+		res.SetString(s.underlying)
+
+	case reflect.Bool:
+		b, ok := v.(boolValue)
+		if !ok {
+			return res, mismatch()
+		}
+		res.SetBool(b.underlying)
+
+	case reflect.Slice:
+		elems, ok := AsList(v)
+		if !ok {
+			return res, mismatch()
+		}
+
+		res = reflect.MakeSlice(t, len(elems), len(elems))
+		for i, e := range elems {
+			ev, err := fromValue(e, t.Elem())
+			if err != nil {
+				return res, errs.Wrapf(err, "element %d", i)
+			}
+			res.Index(i).Set(ev)
+		}
+
+	default:
+		panic(fmt.Sprintf("unconvertible type should have been rejected: %s", t))
+	}
+
+	return res, nil
+}
+
+// integerArg returns v, which is being converted to the integer type t, as a
+// big integer. Inexact and fractional numbers are not converted, since doing so
+// would lose precision.
+func integerArg(v value, t reflect.Type) (*big.Int, error) {
+	switch v := v.(type) {
+	case numberValue, bigValue:
+		return toInteger(v), nil
+	case floatValue, ratValue:
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want exact integer for %s, got %s", t, Repr(v))
+	default:
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want exact integer for %s, got %s", t, typeName(v))
+	}
+}
+
+// toValue converts a Go value of a type accepted by convertibleType to a
+// Scheme value.
+func toValue(rv reflect.Value) value {
+	if rv.Type() == valueReflectType {
+		if rv.IsNil() {
+			return nullValue{}
+		}
+		return rv.Interface().(value)
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return normalizeInt(big.NewInt(rv.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return normalizeInt(new(big.Int).SetUint64(rv.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return floatValue{rv.Float()}
+	case reflect.String:
+		return newString(rv.String())
+	case reflect.Bool:
+		return boolValue{rv.Bool()}
+	case reflect.Slice:
+		vals := make([]value, rv.Len())
+		for i := range vals {
+			vals[i] = toValue(rv.Index(i))
+		}
+		return makeList(vals)
+	}
+
+	panic(fmt.Sprintf("unconvertible type should have been rejected: %s", rv.Type()))
+}
@@ -0,0 +1,232 @@
+package scheme
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestDefineFunc(t *testing.T) {
+	errTest := errors.New("test error")
+
+	in := NewInterpreter()
+
+	funcs := map[string]interface{}{
+		"go-add":    func(a, b int) int { return a + b },
+		"go-repeat": func(s string, n uint8) string { return strings.Repeat(s, int(n)) },
+		"go-not":    func(b bool) bool { return !b },
+		"go-half":   func(f float64) float64 { return f / 2 },
+		"go-sum": func(ns ...int) int {
+			total := 0
+			for _, n := range ns {
+				total += n
+			}
+			return total
+		},
+		"go-join":  func(sep string, parts []string) string { return strings.Join(parts, sep) },
+		"go-split": func(s string) []string { return strings.Split(s, ",") },
+		"go-check": func(n int) (bool, error) {
+			if n < 0 {
+				return false, errTest
+			}
+			return n > 10, nil
+		},
+		"go-fail":  func() error { return errTest },
+		"go-noop":  func() {},
+		"go-first": func(l []Value) Value { return l[0] },
+		"go-any":   func(v interface{}) bool { _, ok := v.(Value); return ok },
+		"go-max":   func() uint64 { return math.MaxUint64 },
+		"go-pred":  func(n uint64) uint64 { return n - 1 },
+	}
+
+	for name, fn := range funcs {
+		if err := in.DefineFunc(name, fn); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+
+	cases := []struct {
+		src     string
+		want    value
+		wantErr error
+	}{
+		{
+			src:  `(go-add 1 2)`,
+			want: numberValue{3},
+		},
+		{
+			src:  `(go-add (go-add 1 2) 3)`,
+			want: numberValue{6},
+		},
+		{
+			src:  `(let ((f go-add)) (f 4 5))`,
+			want: numberValue{9},
+		},
+		{
+			src:  `(go-repeat "ab" 3)`,
+			want: newString("ababab"),
+		},
+		{
+			src:  `(go-not #f)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(go-half 3)`,
+			want: floatValue{1.5},
+		},
+		{
+			src:  `(go-half 1/2)`,
+			want: floatValue{0.25},
+		},
+		{
+			src:  `(go-sum)`,
+			want: numberValue{0},
+		},
+		{
+			src:  `(go-sum 1 2 3 4)`,
+			want: numberValue{10},
+		},
+		{
+			src:  `(go-join "-" (list "a" "b" "c"))`,
+			want: newString("a-b-c"),
+		},
+		{
+			src:  `(go-split "a,b")`,
+			want: makeList([]value{newString("a"), newString("b")}),
+		},
+		{
+			src:  `(go-check 11)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(go-noop)`,
+			want: nullValue{},
+		},
+		{
+			src:  `(go-first (list (list 1) 2))`,
+			want: makeList([]value{numberValue{1}}),
+		},
+		{
+			src:  `(go-any 1)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(go-max)`,
+			want: bigValue{new(big.Int).SetUint64(math.MaxUint64)},
+		},
+		{
+			src:  `(go-pred (go-max))`,
+			want: bigValue{new(big.Int).SetUint64(math.MaxUint64 - 1)},
+		},
+		{
+			src:  `(go-pred 1)`,
+			want: numberValue{0},
+		},
+		{
+			src:     `(go-check -1)`,
+			wantErr: errTest,
+		},
+		{
+			src:     `(go-fail)`,
+			wantErr: errTest,
+		},
+		{
+			src:     `(go-add 1)`,
+			wantErr: errWrongNumberOfArguments,
+		},
+		{
+			src:     `(go-add 1 2 3)`,
+			wantErr: errWrongNumberOfArguments,
+		},
+		{
+			src:     `(go-add 1 "2")`,
+			wantErr: errInvalidArgumentType,
+		},
+		{
+			src:     `(go-add 1.5 1)`,
+			wantErr: errInvalidArgumentType,
+		},
+		{
+			src:     `(go-add 1/2 1)`,
+			wantErr: errInvalidArgumentType,
+		},
+		{
+			src:     `(go-add 100000000000000000000 1)`,
+			wantErr: errInvalidArgumentType,
+		},
+		{
+			src:     `(go-pred 18446744073709551616)`,
+			wantErr: errInvalidArgumentType,
+		},
+		{
+			src:     `(go-repeat "a" 256)`,
+			wantErr: errInvalidArgumentType,
+		},
+		{
+			src:     `(go-repeat "a" -1)`,
+			wantErr: errInvalidArgumentType,
+		},
+		{
+			src:     `(go-sum 1 #t)`,
+			wantErr: errInvalidArgumentType,
+		},
+		{
+			src:     `(go-join "-" (list "a" 1))`,
+			wantErr: errInvalidArgumentType,
+		},
+		{
+			src:     `(go-join "-" "a")`,
+			wantErr: errInvalidArgumentType,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := in.Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+		}
+
+		if c.wantErr == nil && !reflect.DeepEqual(got, c.want) {
+			t.Errorf("value:\ngot:  %v\nwant: %v", got, c.want)
+		}
+	}
+
+	_, err := in.Eval(`(go-add 1 "2")`)
+	if want := `1:1: (go-add 1 "2"): go-add: argument 2: bad argument type: want exact integer for int, got string`; err == nil || err.Error() != want {
+		t.Errorf("error message:\ngot:  %v\nwant: %v", err, want)
+	}
+
+	_, err = in.Eval(`(go-add 1.5 1)`)
+	if want := `1:1: (go-add 1.5 1): go-add: argument 1: bad argument type: want exact integer for int, got 1.5`; err == nil || err.Error() != want {
+		t.Errorf("error message:\ngot:  %v\nwant: %v", err, want)
+	}
+}
+
+func TestDefineFuncUnsupported(t *testing.T) {
+	cases := []interface{}{
+		1,
+		func(c complex128) {},
+		func(m map[string]int) {},
+		func() (int, int) { return 0, 0 },
+		func() (int, bool, error) { return 0, false, nil },
+		func() interface{} { return nil },
+		func(cs ...complex128) {},
+	}
+
+	for i, fn := range cases {
+		t.Logf("Case %d: %T", i, fn)
+
+		err := NewInterpreter().DefineFunc("f", fn)
+		if errs.Root(err) != errUnsupportedFuncType {
+			t.Errorf("error:\ngot:  %v\nwant: %v", err, errUnsupportedFuncType)
+		}
+	}
+}
@@ -0,0 +1,623 @@
+package scheme
+
+import (
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"scgeme/errs"
+)
+
+// Numbers form a tower of exact integers, exact rationals and inexact reals.
+// Exact integers are numberValue when they fit in an int and bigValue
+// otherwise, exact non-integers are ratValue, and inexact numbers are
+// floatValue. The results of exact arithmetic are normalized to the smallest
+// of these representations, so that each exact number has only one.
+
+type bigValue struct {
+	underlying *big.Int
+}
+
+func (_ bigValue) valueType() {
+	// does nothing
+}
+
+func (v bigValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case bigValue:
+		return v.underlying.Cmp(other.underlying) == 0, nil
+	default:
+		return false, nil
+	}
+}
+
+func (v bigValue) greaterThan(other value) (bool, error) {
+	return numberGreaterThan(v, other)
+}
+
+type ratValue struct {
+	underlying *big.Rat
+}
+
+func (_ ratValue) valueType() {
+	// does nothing
+}
+
+func (v ratValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case ratValue:
+		return v.underlying.Cmp(other.underlying) == 0, nil
+	default:
+		return false, nil
+	}
+}
+
+func (v ratValue) greaterThan(other value) (bool, error) {
+	return numberGreaterThan(v, other)
+}
+
+type floatValue struct {
+	underlying float64
+}
+
+func (_ floatValue) valueType() {
+	// does nothing
+}
+
+func (v floatValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case floatValue:
+		// As for eqv?, floats are the same if their bits are, so that NaN
+		// is the same as itself but 0.0 and -0.0 differ.
+		return math.Float64bits(v.underlying) == math.Float64bits(other.underlying), nil
+	default:
+		return false, nil
+	}
+}
+
+func (v floatValue) greaterThan(other value) (bool, error) {
+	return numberGreaterThan(v, other)
+}
+
+func numberGreaterThan(v, other value) (bool, error) {
+	if !isNumber(other) {
+		return false, errIncomparableValueTypes
+	}
+
+	c, ok := compareNumbers(v, other)
+	return ok && c > 0, nil
+}
+
+func isNumber(v value) bool {
+	switch v.(type) {
+	case numberValue, bigValue, ratValue, floatValue:
+		return true
+	default:
+		return false
+	}
+}
+
+// isInteger reports whether v is an integer, exact or inexact.
+func isInteger(v value) bool {
+	switch v := v.(type) {
+	case numberValue, bigValue:
+		return true
+	case floatValue:
+		return !math.IsInf(v.underlying, 0) && v.underlying == math.Trunc(v.underlying)
+	default:
+		return false
+	}
+}
+
+func isExact(v value) bool {
+	_, inexact := v.(floatValue)
+	return !inexact
+}
+
+// normalizeInt returns the exact integer n in its smallest representation.
+func normalizeInt(n *big.Int) value {
+	if n.IsInt64() {
+		if i := n.Int64(); int64(int(i)) == i {
+			return numberValue{int(i)}
+		}
+	}
+	return bigValue{n}
+}
+
+// normalizeRat returns the exact number r in its smallest representation.
+func normalizeRat(r *big.Rat) value {
+	if r.IsInt() {
+		return normalizeInt(new(big.Int).Set(r.Num()))
+	}
+	return ratValue{r}
+}
+
+// toRat returns the exact number v as a rational. The result must not be
+// modified.
+func toRat(v value) *big.Rat {
+	switch v := v.(type) {
+	case numberValue:
+		return new(big.Rat).SetInt64(int64(v.underlying))
+	case bigValue:
+		return new(big.Rat).SetInt(v.underlying)
+	case ratValue:
+		return v.underlying
+	default:
+		panic("not an exact number")
+	}
+}
+
+// toInteger returns the integer v as a big integer. The result must not be
+// modified.
+func toInteger(v value) *big.Int {
+	switch v := v.(type) {
+	case numberValue:
+		return big.NewInt(int64(v.underlying))
+	case bigValue:
+		return v.underlying
+	case floatValue:
+		n, _ := big.NewFloat(v.underlying).Int(nil)
+		return n
+	default:
+		panic("not an integer")
+	}
+}
+
+// toFloat returns the number v as an inexact number.
+func toFloat(v value) float64 {
+	switch v := v.(type) {
+	case numberValue:
+		return float64(v.underlying)
+	case bigValue:
+		f, _ := new(big.Float).SetInt(v.underlying).Float64()
+		return f
+	case ratValue:
+		f, _ := v.underlying.Float64()
+		return f
+	case floatValue:
+		return v.underlying
+	default:
+		panic("not a number")
+	}
+}
+
+// toExact returns the exact number closest to v. Infinities and NaN have no
+// exact equivalent.
+func toExact(v value) (value, bool) {
+	f, ok := v.(floatValue)
+	if !ok {
+		return v, true
+	}
+
+	if math.IsInf(f.underlying, 0) || math.IsNaN(f.underlying) {
+		return nil, false
+	}
+
+	return normalizeRat(new(big.Rat).SetFloat64(f.underlying)), true
+}
+
+// arithmetic applies the operator op, one of + - * and /, to the numbers a and
+// b. The result is inexact if either operand is, and exact otherwise.
+func arithmetic(op byte, a, b value) (value, error) {
+	for _, v := range []value{a, b} {
+		if !isNumber(v) {
+			return nil, errs.WrapAfterf(errInvalidArgumentType, "want number, got %s", typeName(v))
+		}
+	}
+
+	if x, ok := a.(numberValue); ok {
+		if y, ok := b.(numberValue); ok {
+			if v, ok := fixnumArithmetic(op, x.underlying, y.underlying); ok {
+				return v, nil
+			}
+		}
+	}
+
+	if !isExact(a) || !isExact(b) {
+		x, y := toFloat(a), toFloat(b)
+
+		switch op {
+		case '+':
+			return floatValue{x + y}, nil
+		case '-':
+			return floatValue{x - y}, nil
+		case '*':
+			return floatValue{x * y}, nil
+		default:
+			return floatValue{x / y}, nil
+		}
+	}
+
+	x, y := toRat(a), toRat(b)
+	res := new(big.Rat)
+
+	switch op {
+	case '+':
+		res.Add(x, y)
+	case '-':
+		res.Sub(x, y)
+	case '*':
+		res.Mul(x, y)
+	default:
+		if y.Sign() == 0 {
+			return nil, errDivideByZero
+		}
+		res.Quo(x, y)
+	}
+
+	return normalizeRat(res), nil
+}
+
+// fixnumArithmetic applies op to x and y, if the result is an integer that
+// fits in an int.
+func fixnumArithmetic(op byte, x, y int) (value, bool) {
+	switch op {
+	case '+':
+		s := x + y
+		if (x >= 0) == (y >= 0) && (s >= 0) != (x >= 0) {
+			return nil, false
+		}
+		return numberValue{s}, true
+	case '-':
+		d := x - y
+		if (x >= 0) != (y >= 0) && (d >= 0) != (x >= 0) {
+			return nil, false
+		}
+		return numberValue{d}, true
+	case '*':
+		if x == 0 || y == 0 {
+			return numberValue{0}, true
+		}
+		p := x * y
+		if p/y != x || (x == -1 && y == math.MinInt) || (y == -1 && x == math.MinInt) {
+			return nil, false
+		}
+		return numberValue{p}, true
+	default:
+		if y == 0 || x%y != 0 || (x == math.MinInt && y == -1) {
+			return nil, false
+		}
+		return numberValue{x / y}, true
+	}
+}
+
+// compareNumbers returns -1, 0 or 1 as the number a is less than, equal to or
+// greater than the number b. Exact and inexact numbers are compared exactly.
+// ok is false if either is NaN, which is not ordered.
+func compareNumbers(a, b value) (c int, ok bool) {
+	if x, ok := a.(numberValue); ok {
+		if y, ok := b.(numberValue); ok {
+			switch {
+			case x.underlying < y.underlying:
+				return -1, true
+			case x.underlying > y.underlying:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+
+	if !isExact(a) || !isExact(b) {
+		x, y := toFloat(a), toFloat(b)
+
+		switch {
+		case math.IsNaN(x) || math.IsNaN(y):
+			return 0, false
+		case math.IsInf(x, 0) || math.IsInf(y, 0) || (!isExact(a) && !isExact(b)):
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+
+	ra, _ := toExact(a)
+	rb, _ := toExact(b)
+	return toRat(ra).Cmp(toRat(rb)), true
+}
+
+// integerDivision applies op, one of quotient, remainder and modulo, to the
+// integers a and b. The quotient is truncated towards zero, the remainder has
+// the sign of a and the modulo has the sign of b.
+func integerDivision(op string, a, b value) (value, error) {
+	if x, ok := a.(numberValue); ok {
+		if y, ok := b.(numberValue); ok && y.underlying != 0 && !(x.underlying == math.MinInt && y.underlying == -1) {
+			q, r := x.underlying/y.underlying, x.underlying%y.underlying
+
+			switch op {
+			case "quotient":
+				return numberValue{q}, nil
+			case "modulo":
+				if r != 0 && (r < 0) != (y.underlying < 0) {
+					r += y.underlying
+				}
+			}
+
+			return numberValue{r}, nil
+		}
+	}
+
+	x, y := toInteger(a), toInteger(b)
+	if y.Sign() == 0 {
+		return nil, errDivideByZero
+	}
+
+	q, r := new(big.Int).QuoRem(x, y, new(big.Int))
+
+	res := r
+	switch op {
+	case "quotient":
+		res = q
+	case "modulo":
+		if r.Sign() != 0 && r.Sign() != y.Sign() {
+			r.Add(r, y)
+		}
+	}
+
+	if !isExact(a) || !isExact(b) {
+		return floatValue{toFloat(bigValue{res})}, nil
+	}
+
+	return normalizeInt(res), nil
+}
+
+// roundNumber rounds v to an integer, in the direction given by mode: floor,
+// ceiling, truncate, or round, which rounds to even on ties. The result is
+// exact if v is.
+func roundNumber(mode string, v value) value {
+	switch v := v.(type) {
+	case floatValue:
+		switch mode {
+		case "floor":
+			return floatValue{math.Floor(v.underlying)}
+		case "ceiling":
+			return floatValue{math.Ceil(v.underlying)}
+		case "truncate":
+			return floatValue{math.Trunc(v.underlying)}
+		default:
+			return floatValue{math.RoundToEven(v.underlying)}
+		}
+	case ratValue:
+		// Since the denominator is positive, Euclidean division gives the
+		// floor, and since v is not an integer the remainder is not zero.
+		num, den := v.underlying.Num(), v.underlying.Denom()
+		q, m := new(big.Int).DivMod(num, den, new(big.Int))
+
+		one := big.NewInt(1)
+		switch mode {
+		case "ceiling":
+			q.Add(q, one)
+		case "truncate":
+			if num.Sign() < 0 {
+				q.Add(q, one)
+			}
+		case "round":
+			c := new(big.Int).Lsh(m, 1).Cmp(den)
+			if c > 0 || (c == 0 && q.Bit(0) == 1) {
+				q.Add(q, one)
+			}
+		}
+
+		return normalizeInt(q)
+	default:
+		return v
+	}
+}
+
+// exptNumber raises base to the power e. The result is exact if base is
+// exact and e is an exact integer.
+func exptNumber(base, e value) (value, error) {
+	if n, ok := e.(numberValue); ok && isExact(base) {
+		k := big.NewInt(int64(n.underlying))
+		r := toRat(base)
+
+		num := new(big.Int).Exp(r.Num(), new(big.Int).Abs(k), nil)
+		den := new(big.Int).Exp(r.Denom(), new(big.Int).Abs(k), nil)
+
+		if k.Sign() < 0 {
+			if num.Sign() == 0 {
+				return nil, errDivideByZero
+			}
+			num, den = den, num
+		}
+
+		return normalizeRat(new(big.Rat).SetFrac(num, den)), nil
+	}
+
+	return floatValue{math.Pow(toFloat(base), toFloat(e))}, nil
+}
+
+// sqrtNumber returns the square root of v. It is exact if v is an exact
+// square, and inexact otherwise; negative numbers have no real square root,
+// so their square root is NaN.
+func sqrtNumber(v value) value {
+	if isExact(v) {
+		if r := toRat(v); r.Sign() >= 0 {
+			num, numOk := exactSqrt(r.Num())
+			den, denOk := exactSqrt(r.Denom())
+			if numOk && denOk {
+				return normalizeRat(new(big.Rat).SetFrac(num, den))
+			}
+		}
+	}
+
+	return floatValue{math.Sqrt(toFloat(v))}
+}
+
+// exactSqrt returns the integer square root of the non-negative integer n,
+// and whether it is exact.
+func exactSqrt(n *big.Int) (*big.Int, bool) {
+	s := new(big.Int).Sqrt(n)
+	return s, new(big.Int).Mul(s, s).Cmp(n) == 0
+}
+
+var radixPrefixes = map[byte]int{'b': 2, 'o': 8, 'd': 10, 'x': 16}
+
+var decimalRegexp = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// parseNumber parses a numeric literal: an integer, a ratio n/d or, in
+// decimal, a number with a fraction and exponent, optionally preceded by a
+// radix prefix #x, #o, #b or #d and an exactness prefix #e or #i. Without a
+// radix prefix, digits are read in the given radix.
+func parseNumber(s string, defaultRadix int) (value, bool) {
+	// Fast path for the common case of a decimal integer.
+	if defaultRadix == 10 {
+		if n, err := strconv.Atoi(s); err == nil {
+			return numberValue{n}, true
+		}
+	}
+
+	radix := 0
+	var exactness byte
+
+	for len(s) >= 2 && s[0] == '#' {
+		switch p := s[1] | 0x20; p {
+		case 'x', 'o', 'b', 'd':
+			if radix != 0 {
+				return nil, false
+			}
+			radix = radixPrefixes[p]
+		case 'e', 'i':
+			if exactness != 0 {
+				return nil, false
+			}
+			exactness = p
+		default:
+			return nil, false
+		}
+		s = s[2:]
+	}
+
+	if radix == 0 {
+		radix = defaultRadix
+	}
+
+	if exactness == 'e' && radix == 10 && decimalRegexp.MatchString(s) {
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return nil, false
+		}
+		return normalizeRat(r), true
+	}
+
+	v, ok := parseReal(s, radix)
+	if !ok {
+		return nil, false
+	}
+
+	switch exactness {
+	case 'e':
+		return toExact(v)
+	case 'i':
+		return floatValue{toFloat(v)}, true
+	default:
+		return v, true
+	}
+}
+
+func parseReal(s string, radix int) (value, bool) {
+	switch s {
+	case "+inf.0":
+		return floatValue{math.Inf(1)}, true
+	case "-inf.0":
+		return floatValue{math.Inf(-1)}, true
+	case "+nan.0", "-nan.0":
+		return floatValue{math.NaN()}, true
+	}
+
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		num, ok := parseInteger(s[:i], radix)
+		if !ok {
+			return nil, false
+		}
+
+		den, ok := parseInteger(s[i+1:], radix)
+		if !ok || den.Sign() <= 0 || s[i+1] == '+' {
+			return nil, false
+		}
+
+		return normalizeRat(new(big.Rat).SetFrac(num, den)), true
+	}
+
+	if n, ok := parseInteger(s, radix); ok {
+		return normalizeInt(n), true
+	}
+
+	if radix == 10 && decimalRegexp.MatchString(s) {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, false
+		}
+		return floatValue{f}, true
+	}
+
+	return nil, false
+}
+
+// parseInteger parses an optionally signed integer in the given radix.
+func parseInteger(s string, radix int) (*big.Int, bool) {
+	digits := strings.TrimLeft(s, "+-")
+	if digits == "" || len(s)-len(digits) > 1 {
+		return nil, false
+	}
+
+	return new(big.Int).SetString(s, radix)
+}
+
+// formatNumber returns the representation of the number v in the given radix.
+// Inexact numbers can only be written in decimal.
+func formatNumber(v value, radix int) (string, bool) {
+	if radix == 10 {
+		return reprNumber(v), true
+	}
+
+	switch v := v.(type) {
+	case numberValue:
+		return strconv.FormatInt(int64(v.underlying), radix), true
+	case bigValue:
+		return v.underlying.Text(radix), true
+	case ratValue:
+		return v.underlying.Num().Text(radix) + "/" + v.underlying.Denom().Text(radix), true
+	default:
+		return "", false
+	}
+}
+
+// reprNumber returns the external representation of the number v.
+func reprNumber(v value) string {
+	switch v := v.(type) {
+	case numberValue:
+		return strconv.Itoa(v.underlying)
+	case bigValue:
+		return v.underlying.String()
+	case ratValue:
+		return v.underlying.RatString()
+	case floatValue:
+		f := v.underlying
+		switch {
+		case math.IsNaN(f):
+			return "+nan.0"
+		case math.IsInf(f, 1):
+			return "+inf.0"
+		case math.IsInf(f, -1):
+			return "-inf.0"
+		}
+
+		// An inexact integer is written with a decimal point, to tell it
+		// apart from an exact one.
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		return s
+	default:
+		panic("not a number")
+	}
+}
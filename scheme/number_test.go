@@ -0,0 +1,287 @@
+package scheme
+
+import (
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestParseNumber(t *testing.T) {
+	cases := []struct {
+		src    string
+		want   string
+		wantOk bool
+	}{
+		{src: `42`, want: `42`, wantOk: true},
+		{src: `-7`, want: `-7`, wantOk: true},
+		{src: `+7`, want: `7`, wantOk: true},
+		{src: `123456789012345678901234567890`, want: `123456789012345678901234567890`, wantOk: true},
+		{src: `1/3`, want: `1/3`, wantOk: true},
+		{src: `-6/4`, want: `-3/2`, wantOk: true},
+		{src: `4/2`, want: `2`, wantOk: true},
+		{src: `1.5`, want: `1.5`, wantOk: true},
+		{src: `.5`, want: `0.5`, wantOk: true},
+		{src: `2.`, want: `2.0`, wantOk: true},
+		{src: `1e3`, want: `1000.0`, wantOk: true},
+		{src: `-2.5e-3`, want: `-0.0025`, wantOk: true},
+		{src: `#xff`, want: `255`, wantOk: true},
+		{src: `#XFF`, want: `255`, wantOk: true},
+		{src: `#b-101`, want: `-5`, wantOk: true},
+		{src: `#o17`, want: `15`, wantOk: true},
+		{src: `#d10`, want: `10`, wantOk: true},
+		{src: `#x1/a`, want: `1/10`, wantOk: true},
+		{src: `#e1.5`, want: `3/2`, wantOk: true},
+		{src: `#e1e2`, want: `100`, wantOk: true},
+		{src: `#i1/2`, want: `0.5`, wantOk: true},
+		{src: `#i3`, want: `3.0`, wantOk: true},
+		{src: `#x#e10`, want: `16`, wantOk: true},
+		{src: `#e#x10`, want: `16`, wantOk: true},
+		{src: `+inf.0`, want: `+inf.0`, wantOk: true},
+		{src: `-inf.0`, want: `-inf.0`, wantOk: true},
+		{src: `+nan.0`, want: `+nan.0`, wantOk: true},
+		{src: `1/0`},
+		{src: `1/-2`},
+		{src: `1/+2`},
+		{src: `--1`},
+		{src: `+`},
+		{src: `-`},
+		{src: `...`},
+		{src: `1.2.3`},
+		{src: `#x1.5`},
+		{src: `#b2`},
+		{src: `#x#x1`},
+		{src: `#e#i1`},
+		{src: `#e+inf.0`},
+		{src: `abc`},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %s", i, c.src)
+
+		got, ok := parseNumber(c.src, 10)
+		if ok != c.wantOk {
+			t.Errorf("ok:\ngot:  %v\nwant: %v", ok, c.wantOk)
+			continue
+		}
+
+		if ok && reprNumber(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", reprNumber(got), c.want)
+		}
+	}
+}
+
+func TestNumericTower(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{
+			src:  `(+ 9223372036854775807 1)`,
+			want: `9223372036854775808`,
+		},
+		{
+			src:  `(- -9223372036854775808 1)`,
+			want: `-9223372036854775809`,
+		},
+		{
+			src:  `(- 9223372036854775808 1)`,
+			want: `9223372036854775807`,
+		},
+		{
+			src: `
+				(define (fact n) (if (= n 0) 1 (* n (fact (- n 1)))))
+				(fact 25)`,
+			want: `15511210043330985984000000`,
+		},
+		{
+			src:  `(/ (fact 25) (fact 24))`,
+			want: `25`,
+		},
+		{
+			src:  `(list #xff #xFF #xabc #x-ff #XA #b101 #o17 #e#xff)`,
+			want: `(255 255 2748 -255 10 5 15 255)`,
+		},
+		{
+			src:  `(list '#xff (number? '#xabc))`,
+			want: `(255 #t)`,
+		},
+		{
+			src:  `(+ #xffffffffffffffff 1)`,
+			want: `18446744073709551616`,
+		},
+		{
+			src:     `#xfg`,
+			wantErr: errBindingNotFound,
+		},
+		{
+			src:  `(+ 1/2 1/3)`,
+			want: `5/6`,
+		},
+		{
+			src:  `(* 2/3 3/2)`,
+			want: `1`,
+		},
+		{
+			src:  `(+ 1/2 0.5)`,
+			want: `1.0`,
+		},
+		{
+			src:  `(* 2 1.5)`,
+			want: `3.0`,
+		},
+		{
+			src:  `(- 0.5 1/2)`,
+			want: `0.0`,
+		},
+		{
+			src:  `(/ 1 0.0)`,
+			want: `+inf.0`,
+		},
+		{
+			src:  `(= 1/2 0.5)`,
+			want: `#t`,
+		},
+		{
+			src:  `(= 1 1.0)`,
+			want: `#t`,
+		},
+		{
+			src:  `(= 1/3 0.3333333333333333)`,
+			want: `#f`,
+		},
+		{
+			src:  `(> 9223372036854775808 9223372036854775807)`,
+			want: `#t`,
+		},
+		{
+			src:  `(> 1/2 1/3)`,
+			want: `#t`,
+		},
+		{
+			src:  `(> 1 +inf.0)`,
+			want: `#f`,
+		},
+		{
+			src:  `(> +nan.0 1)`,
+			want: `#f`,
+		},
+		{
+			src:  `(eqv? 1 1.0)`,
+			want: `#f`,
+		},
+		{
+			src:  `(eqv? 1/2 2/4)`,
+			want: `#t`,
+		},
+		{
+			src:  `(equal? (list 100000000000000000000 1.5) (list 100000000000000000000 1.5))`,
+			want: `#t`,
+		},
+		{
+			src:  `'(1/2 #x10 1e1)`,
+			want: `(1/2 16 10.0)`,
+		},
+		{
+			src:     `(/ 1/2 0)`,
+			wantErr: errDivideByZero,
+		},
+		{
+			src:     `(+ 1.5 "a")`,
+			wantErr: errInvalidArgumentType,
+		},
+	}
+
+	in := NewInterpreter()
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := in.Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
+		}
+	}
+}
+
+func TestMathLibrary(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{src: `(list (quotient 17 5) (remainder 17 5) (modulo 17 5))`, want: `(3 2 2)`},
+		{src: `(list (quotient -17 5) (remainder -17 5) (modulo -17 5))`, want: `(-3 -2 3)`},
+		{src: `(list (quotient 17 -5) (remainder 17 -5) (modulo 17 -5))`, want: `(-3 2 -3)`},
+		{src: `(modulo 100000000000000000000 7)`, want: `2`},
+		{src: `(quotient -9223372036854775808 -1)`, want: `9223372036854775808`},
+		{src: `(remainder 17.0 5)`, want: `2.0`},
+		{src: `(list (abs -5) (abs 5) (abs -1/2) (abs -2.5))`, want: `(5 5 1/2 2.5)`},
+		{src: `(abs -9223372036854775808)`, want: `9223372036854775808`},
+		{src: `(list (min 3 1 2) (max 3 1 2) (max 1/2 1/3))`, want: `(1 3 1/2)`},
+		{src: `(list (min 1 2.0) (max 1 2.0))`, want: `(1.0 2.0)`},
+		{src: `(max 1 +nan.0 2)`, want: `+nan.0`},
+		{src: `(list (gcd) (gcd 12 18) (gcd -4 6 8) (gcd 0 5))`, want: `(0 6 2 5)`},
+		{src: `(list (lcm) (lcm 4 6) (lcm -3 4) (lcm 0 5))`, want: `(1 12 12 0)`},
+		{src: `(gcd 12.0 18)`, want: `6.0`},
+		{src: `(list (expt 2 10) (expt 2 -2) (expt 2/3 3) (expt 0 0))`, want: `(1024 1/4 8/27 1)`},
+		{src: `(expt 2 100)`, want: `1267650600228229401496703205376`},
+		{src: `(list (expt 2.0 3) (expt 4 1/2) (expt 2 0.5))`, want: `(8.0 2.0 1.4142135623730951)`},
+		{src: `(list (sqrt 16) (sqrt 1/4) (sqrt 2) (sqrt 16.0))`, want: `(4 1/2 1.4142135623730951 4.0)`},
+		{src: `(sqrt (expt 10 40))`, want: `100000000000000000000`},
+		{src: `(list (exact-integer-sqrt 17) (exact-integer-sqrt 16))`, want: `((4 1) (4 0))`},
+		{src: `(list (exp 0) (log 1) (log 8 2) (sin 0) (cos 0) (atan 1 1))`, want: `(1.0 0.0 3.0 0.0 1.0 0.7853981633974483)`},
+		{src: `(list (floor 5/2) (ceiling 5/2) (round 5/2) (truncate 5/2))`, want: `(2 3 2 2)`},
+		{src: `(list (floor -5/2) (ceiling -5/2) (round -5/2) (truncate -5/2))`, want: `(-3 -2 -2 -2)`},
+		{src: `(list (round 7/2) (round -7/2) (round 8/3) (round 3))`, want: `(4 -4 3 3)`},
+		{src: `(list (floor -2.5) (ceiling -2.5) (round -2.5) (truncate -2.5) (round 3.5))`, want: `(-3.0 -2.0 -2.0 -2.0 4.0)`},
+		{src: `(list (exact 2.5) (exact 2.0) (inexact 1/4) (exact->inexact 3))`, want: `(5/2 2 0.25 3.0)`},
+		{src: `(list (number->string 255) (number->string 255 16) (number->string -5 2) (number->string 1/3 8) (number->string 1.5))`, want: `("255" "ff" "-101" "1/3" "1.5")`},
+		{src: `(list (string->number "42") (string->number "ff" 16) (string->number "#xff") (string->number "1e2") (string->number "abc"))`, want: `(42 255 255 100.0 #f)`},
+		{src: `(string->number "101" 2)`, want: `5`},
+		{src: `(list (number? 1) (number? 1.5) (number? "1") (real? 1/2))`, want: `(#t #t #f #t)`},
+		{src: `(list (integer? 1) (integer? 1.0) (integer? 1.5) (integer? 1/2) (integer? 'a))`, want: `(#t #t #f #f #f)`},
+		{src: `(list (rational? 1/2) (rational? 0.5) (rational? +inf.0) (exact-integer? 1) (exact-integer? 1.0))`, want: `(#t #t #f #t #f)`},
+		{src: `(list (exact? 1/2) (exact? 0.5) (inexact? 0.5))`, want: `(#t #f #t)`},
+		{src: `(list (nan? +nan.0) (infinite? -inf.0) (finite? 1.0) (finite? +inf.0))`, want: `(#t #t #t #f)`},
+		{src: `(list (zero? 0) (zero? 0.0) (zero? 1/2) (positive? 1/2) (negative? -1.5) (positive? +nan.0))`, want: `(#t #t #f #t #t #f)`},
+		{src: `(list (odd? 3) (even? 3) (even? 0) (odd? -1) (even? 100000000000000000000) (even? 4.0))`, want: `(#t #f #t #t #t #t)`},
+		{src: `(list (even? -2) (odd? -2) (even? -100000000000000000001))`, want: `(#t #f #f)`},
+		{src: `(even? 1.5)`, wantErr: errInvalidArgumentType},
+		{src: `(even? 1 2)`, wantErr: errWrongNumberOfArguments},
+		{src: `(square 1/2)`, want: `1/4`},
+		{src: `(quotient 1 0)`, wantErr: errDivideByZero},
+		{src: `(modulo 1.5 1)`, wantErr: errInvalidArgumentType},
+		{src: `(odd? 1/2)`, wantErr: errInvalidArgumentType},
+		{src: `(zero? "0")`, wantErr: errInvalidArgumentType},
+		{src: `(expt 0 -1)`, wantErr: errDivideByZero},
+		{src: `(exact +inf.0)`, wantErr: errInvalidArgumentType},
+		{src: `(exact-integer-sqrt -1)`, wantErr: errInvalidArgumentType},
+		{src: `(number->string 1.5 2)`, wantErr: errInvalidRadix},
+		{src: `(string->number "1" 3)`, wantErr: errInvalidRadix},
+		{src: `(min)`, wantErr: errWrongNumberOfArguments},
+	}
+
+	in := NewInterpreter()
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := in.Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
+		}
+	}
+}
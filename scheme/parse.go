@@ -0,0 +1,234 @@
+package scheme
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"scgeme/errs"
+)
+
+var (
+	errInvalidClosingBrace = errors.New("invalid closing brace")
+	errUnclosedExpression  = errors.New("unclosed expression")
+	errMissingDatum        = errors.New("datum comment must be followed by a datum")
+	errMissingQuotedDatum  = errors.New("quote must be followed by a datum")
+)
+
+type expression interface {
+	expressionType()
+	location() span
+}
+
+type tokenExpression struct {
+	token string
+	span  span
+	alias *alias // set on identifiers renamed by macro expansion
+}
+
+func (_ *tokenExpression) expressionType() {
+	// does nothing
+}
+
+func (e *tokenExpression) location() span {
+	return e.span
+}
+
+type compoundExpression struct {
+	children []expression
+	span     span
+	vector   bool // written #(children...)
+
+	// expansion is kept from the last time the expression was expanded as a
+	// macro use, so that evaluating it again need not expand it again.
+	expansion *cachedExpansion
+}
+
+func (_ *compoundExpression) expressionType() {
+	// does nothing
+}
+
+func (e *compoundExpression) location() span {
+	return e.span
+}
+
+func isTokenExpression(expr expression) bool {
+	_, ok := expr.(*tokenExpression)
+	return ok
+}
+
+func isCompoundExpression(expr expression) bool {
+	_, ok := expr.(*compoundExpression)
+	return ok
+}
+
+func mustExpressionToken(expr expression) string {
+	res, ok := expr.(*tokenExpression)
+	if !ok {
+		panic(fmt.Sprintf("must be token expression: %v", expr))
+	}
+	return res.token
+}
+
+func mustExpressionChildren(expr expression) []expression {
+	res, ok := expr.(*compoundExpression)
+	if !ok {
+		panic(fmt.Sprintf("must be compound expression: %v", expr))
+	}
+	return res.children
+}
+
+const snippetLength = 40
+
+// snippet returns an abbreviated rendering of expr for use in error messages.
+func snippet(expr expression) string {
+	s := []rune(unparse(expr))
+	if len(s) > snippetLength {
+		return string(s[:snippetLength-3]) + "..."
+	}
+	return string(s)
+}
+
+// unparse renders expr back into source form.
+func unparse(expr expression) string {
+	switch e := expr.(type) {
+	case *tokenExpression:
+		if e.token[0] == '"' {
+			return reprString(e.token[1 : len(e.token)-1])
+		}
+		return e.name()
+	case *compoundExpression:
+		parts := make([]string, len(e.children))
+		for i, c := range e.children {
+			parts[i] = unparse(c)
+		}
+		if e.vector {
+			return "#(" + strings.Join(parts, " ") + ")"
+		}
+		return "(" + strings.Join(parts, " ") + ")"
+	default:
+		panic(fmt.Sprintf("invalid expression: %v", expr))
+	}
+}
+
+// parseSource tokenizes and parses src, recording positions against the given
+// file name.
+func parseSource(file, src string) ([]expression, error) {
+	tokens, err := tokenize(file, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(tokens)
+}
+
+func parse(tokens []token) ([]expression, error) {
+	var (
+		res   []expression
+		stack []*compoundExpression
+
+		// bytevectors are the open expressions written #u8(...), which
+		// become single tokens once closed.
+		bytevectors = make(map[*compoundExpression]bool)
+
+		// Datum comments and quote prefixes apply to the next datum to be
+		// completed at the nesting depth where they appeared.
+		pending []prefix
+
+		// add appends a completed datum at the current depth, after applying
+		// any pending prefixes.
+		add = func(n expression) {
+			for len(pending) > 0 && pending[len(pending)-1].depth == len(stack) {
+				p := pending[len(pending)-1]
+				pending = pending[:len(pending)-1]
+
+				if p.tok.text == datumCommentToken {
+					return
+				}
+
+				n = &compoundExpression{
+					children: []expression{
+						&tokenExpression{token: prefixForms[p.tok.text], span: p.tok.span},
+						n,
+					},
+					span: span{p.tok.span.start, n.location().end},
+				}
+			}
+
+			if len(stack) == 0 {
+				res = append(res, n)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			}
+		}
+	)
+
+	for _, t := range tokens {
+		switch t.text {
+		case "(", vectorToken, bytevectorToken:
+			n := new(compoundExpression)
+			n.span.start = t.span.start
+			n.vector = t.text == vectorToken
+			bytevectors[n] = t.text == bytevectorToken
+			stack = append(stack, n)
+
+		case ")":
+			if len(stack) == 0 {
+				return res, errs.Wrap(errInvalidClosingBrace, t.span.String())
+			}
+
+			if len(pending) > 0 && pending[len(pending)-1].depth == len(stack) {
+				return res, pending[len(pending)-1].missing()
+			}
+
+			n := stack[len(stack)-1]
+			n.span.end = t.span.end
+			stack = stack[0 : len(stack)-1]
+
+			if bytevectors[n] {
+				b, err := bytevectorLiteral(n)
+				if err != nil {
+					return res, err
+				}
+				add(b)
+				continue
+			}
+
+			add(n)
+
+		case datumCommentToken, "'", "`", ",", ",@":
+			pending = append(pending, prefix{tok: t, depth: len(stack)})
+
+		default:
+			n := new(tokenExpression)
+			n.token = t.text
+			n.span = t.span
+			add(n)
+		}
+	}
+
+	if len(stack) != 0 {
+		return res, errs.Wrap(errUnclosedExpression, stack[0].span.String())
+	}
+
+	if len(pending) != 0 {
+		return res, errs.Wrap(errUnclosedExpression, pending[0].tok.span.String())
+	}
+
+	return res, nil
+}
+
+// prefix is a datum comment or quote shorthand awaiting its datum.
+type prefix struct {
+	tok   token
+	depth int
+}
+
+// missing returns the error for a prefix that is not followed by a datum.
+func (p prefix) missing() error {
+	if p.tok.text == datumCommentToken {
+		return errs.Wrap(errMissingDatum, p.tok.span.String())
+	}
+	return errs.Wrap(errMissingQuotedDatum, p.tok.span.String())
+}
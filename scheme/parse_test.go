@@ -0,0 +1,324 @@
+package scheme
+
+import (
+	"reflect"
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    []expression
+		wantErr error
+	}{
+		{
+			src:  `foo`,
+			want: []expression{&tokenExpression{token: "foo"}},
+		},
+		{
+			src:  `foo bar`,
+			want: []expression{&tokenExpression{token: "foo"}, &tokenExpression{token: "bar"}},
+		},
+		{
+			src: `(foo)`,
+			want: []expression{&compoundExpression{
+				children: []expression{&tokenExpression{token: "foo"}},
+			}},
+		},
+		{
+			src: `(foo bar)`,
+			want: []expression{&compoundExpression{
+				children: []expression{
+					&tokenExpression{token: "foo"},
+					&tokenExpression{token: "bar"},
+				},
+			}},
+		},
+		{
+			src: `
+				(lambda (foo)
+				  (+ foo foo))
+			`,
+			want: []expression{&compoundExpression{
+				children: []expression{
+					&tokenExpression{token: "lambda"},
+					&compoundExpression{
+						children: []expression{
+							&tokenExpression{token: "foo"},
+						},
+					},
+					&compoundExpression{
+						children: []expression{
+							&tokenExpression{token: "+"},
+							&tokenExpression{token: "foo"},
+							&tokenExpression{token: "foo"},
+						},
+					},
+				},
+			}},
+		},
+		{
+			src:  `foo ; bar`,
+			want: []expression{&tokenExpression{token: "foo"}},
+		},
+		{
+			src:  `foo #| bar |# baz`,
+			want: []expression{&tokenExpression{token: "foo"}, &tokenExpression{token: "baz"}},
+		},
+		{
+			src:  `#;foo bar`,
+			want: []expression{&tokenExpression{token: "bar"}},
+		},
+		{
+			src:  `#; (foo (bar)) baz`,
+			want: []expression{&tokenExpression{token: "baz"}},
+		},
+		{
+			src:  `#; #; foo bar baz`,
+			want: []expression{&tokenExpression{token: "baz"}},
+		},
+		{
+			src: `(foo #;bar baz)`,
+			want: []expression{&compoundExpression{
+				children: []expression{
+					&tokenExpression{token: "foo"},
+					&tokenExpression{token: "baz"},
+				},
+			}},
+		},
+		{
+			src: `(foo #;(bar #;baz) (qux))`,
+			want: []expression{&compoundExpression{
+				children: []expression{
+					&tokenExpression{token: "foo"},
+					&compoundExpression{
+						children: []expression{&tokenExpression{token: "qux"}},
+					},
+				},
+			}},
+		},
+		{
+			src: `'foo`,
+			want: []expression{&compoundExpression{
+				children: []expression{
+					&tokenExpression{token: "quote"},
+					&tokenExpression{token: "foo"},
+				},
+			}},
+		},
+		{
+			src: "`(a ,b ,@c)",
+			want: []expression{&compoundExpression{
+				children: []expression{
+					&tokenExpression{token: "quasiquote"},
+					&compoundExpression{
+						children: []expression{
+							&tokenExpression{token: "a"},
+							&compoundExpression{
+								children: []expression{
+									&tokenExpression{token: "unquote"},
+									&tokenExpression{token: "b"},
+								},
+							},
+							&compoundExpression{
+								children: []expression{
+									&tokenExpression{token: "unquote-splicing"},
+									&tokenExpression{token: "c"},
+								},
+							},
+						},
+					},
+				},
+			}},
+		},
+		{
+			src: `''a`,
+			want: []expression{&compoundExpression{
+				children: []expression{
+					&tokenExpression{token: "quote"},
+					&compoundExpression{
+						children: []expression{
+							&tokenExpression{token: "quote"},
+							&tokenExpression{token: "a"},
+						},
+					},
+				},
+			}},
+		},
+		{
+			src:  `#; 'a b`,
+			want: []expression{&tokenExpression{token: "b"}},
+		},
+		{
+			src: `'#;a b`,
+			want: []expression{&compoundExpression{
+				children: []expression{
+					&tokenExpression{token: "quote"},
+					&tokenExpression{token: "b"},
+				},
+			}},
+		},
+		{
+			src:     `(foo ')`,
+			wantErr: errMissingQuotedDatum,
+		},
+		{
+			src:     `'`,
+			wantErr: errUnclosedExpression,
+		},
+		{
+			src:     `(foo #;)`,
+			wantErr: errMissingDatum,
+		},
+		{
+			src:     `foo #;`,
+			wantErr: errUnclosedExpression,
+		},
+		{
+			src:     `(foo))`,
+			wantErr: errInvalidClosingBrace,
+		},
+		{
+			src:     `(foo`,
+			wantErr: errUnclosedExpression,
+		},
+	}
+
+	for _, c := range cases {
+		got, gotErr := parseSource("", c.src)
+		stripSpans(got)
+
+		if gotErr != nil || c.wantErr != nil {
+			if errs.Root(gotErr) != c.wantErr {
+				t.Errorf("%s:\ngot error:  %v\nwant error: %v", c.src, gotErr, c.wantErr)
+			}
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s:\ngot:  %v\nwant: %v", c.src, got, c.want)
+		}
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	cases := []struct {
+		src  string
+		want string
+	}{
+		{
+			src:  "(foo\n  bar))",
+			want: "test.scm:2:7: invalid closing brace",
+		},
+		{
+			src:  "(foo)\n  (bar\n (baz)",
+			want: "test.scm:2:3: unclosed expression",
+		},
+		{
+			src:  "(foo #;)",
+			want: "test.scm:1:6: datum comment must be followed by a datum",
+		},
+		{
+			src:  "foo\n\"bar",
+			want: "test.scm:2:1: unclosed string",
+		},
+	}
+
+	for _, c := range cases {
+		_, err := parseSource("test.scm", c.src)
+		if err == nil || err.Error() != c.want {
+			t.Errorf("%q:\ngot error:  %v\nwant error: %v", c.src, err, c.want)
+		}
+	}
+}
+
+func TestParseSpans(t *testing.T) {
+	exprs, err := parseSource("f", "(foo\n  (bar \"baz\"))")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pos := func(line, col int) position {
+		return position{file: "f", line: line, col: col}
+	}
+
+	quoted, err := parseSource("f", "(a '(b c))")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outer := exprs[0].(*compoundExpression)
+	quote := quoted[0].(*compoundExpression).children[1].(*compoundExpression)
+	inner := outer.children[1].(*compoundExpression)
+
+	cases := []struct {
+		expr expression
+		want span
+	}{
+		{expr: outer, want: span{pos(1, 1), pos(2, 15)}},
+		{expr: outer.children[0], want: span{pos(1, 2), pos(1, 5)}},
+		{expr: inner, want: span{pos(2, 3), pos(2, 14)}},
+		{expr: inner.children[0], want: span{pos(2, 4), pos(2, 7)}},
+		{expr: inner.children[1], want: span{pos(2, 8), pos(2, 13)}},
+		{expr: quote, want: span{pos(1, 4), pos(1, 10)}},
+		{expr: quote.children[0], want: span{pos(1, 4), pos(1, 5)}},
+	}
+
+	for _, c := range cases {
+		if got := c.expr.location(); got != c.want {
+			t.Errorf("%s:\ngot:  %v - %v\nwant: %v - %v", unparse(c.expr), got.start, got.end, c.want.start, c.want.end)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	cases := []struct {
+		src  string
+		want string
+	}{
+		{
+			src:  `foo`,
+			want: `foo`,
+		},
+		{
+			src:  "(foo   (bar\n \"b\\\"az\"))",
+			want: `(foo (bar "b\"az"))`,
+		},
+		{
+			src:  `(vector-ref #(1 #(2)) 0)`,
+			want: `(vector-ref #(1 #(2)) 0)`,
+		},
+		{
+			src:  "(bytevector-u8-ref #u8( 1\n #xff #;3 ) 0)",
+			want: `(bytevector-u8-ref #u8(1 #xff) 0)`,
+		},
+		{
+			src:  `(define (long-procedure-name argument) (+ argument argument))`,
+			want: `(define (long-procedure-name argument...`,
+		},
+	}
+
+	for _, c := range cases {
+		exprs, err := parseSource("", c.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := snippet(exprs[0]); got != c.want {
+			t.Errorf("got:  %v\nwant: %v", got, c.want)
+		}
+	}
+}
+
+// stripSpans zeroes the spans of exprs and their descendants, so that parse
+// results can be compared against expressions built by hand.
+func stripSpans(exprs []expression) {
+	for _, expr := range exprs {
+		switch e := expr.(type) {
+		case *tokenExpression:
+			e.span = span{}
+		case *compoundExpression:
+			e.span = span{}
+			stripSpans(e.children)
+		}
+	}
+}
@@ -0,0 +1,2148 @@
+package scheme
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"scgeme/errs"
+)
+
+// primitives are the procedures implemented in Go, which stdlib binds under
+// their names. Each is also available, even where its name has been rebound,
+// through the special form (primitive name args...).
+var primitives map[string]func([]value) (value, error)
+
+func init() {
+	primitives = map[string]func([]value) (value, error){
+		"+":        primitiveAdd,
+		"-":        primitiveSubtract,
+		"*":        primitiveMultiply,
+		"/":        primitiveDivide,
+		"=":        primitiveEquals,
+		"<":        comparisonPrimitive(func(c int) bool { return c < 0 }),
+		">":        comparisonPrimitive(func(c int) bool { return c > 0 }),
+		"<=":       comparisonPrimitive(func(c int) bool { return c <= 0 }),
+		">=":       comparisonPrimitive(func(c int) bool { return c >= 0 }),
+		"cons":     primitiveCons,
+		"car":      primitiveCar,
+		"cdr":      primitiveCdr,
+		"pair?":    primitivePair,
+		"set-car!": primitiveSetCar,
+		"set-cdr!": primitiveSetCdr,
+		"eq?":      primitiveEq,
+		"eqv?":     primitiveEqv,
+		"equal?":   primitiveEqual,
+		"null?":    primitiveNull,
+		"symbol?":  typePredicate(isSymbol),
+
+		"number?":            typePredicate(isNumber),
+		"integer?":           typePredicate(isInteger),
+		"rational?":          typePredicate(isRational),
+		"exact-integer?":     typePredicate(isExactInteger),
+		"exact?":             numberPredicate(isExact),
+		"nan?":               numberPredicate(isNaN),
+		"infinite?":          numberPredicate(isInfinite),
+		"zero?":              signPredicate(func(c int) bool { return c == 0 }),
+		"positive?":          signPredicate(func(c int) bool { return c > 0 }),
+		"negative?":          signPredicate(func(c int) bool { return c < 0 }),
+		"odd?":               parityPrimitive(true),
+		"even?":              parityPrimitive(false),
+		"quotient":           divisionPrimitive("quotient"),
+		"remainder":          divisionPrimitive("remainder"),
+		"modulo":             divisionPrimitive("modulo"),
+		"abs":                primitiveAbs,
+		"min":                extremumPrimitive(-1),
+		"max":                extremumPrimitive(1),
+		"gcd":                primitiveGcd,
+		"lcm":                primitiveLcm,
+		"floor":              roundingPrimitive("floor"),
+		"ceiling":            roundingPrimitive("ceiling"),
+		"round":              roundingPrimitive("round"),
+		"truncate":           roundingPrimitive("truncate"),
+		"expt":               primitiveExpt,
+		"sqrt":               primitiveSqrt,
+		"exact-integer-sqrt": primitiveExactIntegerSqrt,
+		"exp":                floatPrimitive(math.Exp),
+		"log":                primitiveLog,
+		"sin":                floatPrimitive(math.Sin),
+		"cos":                floatPrimitive(math.Cos),
+		"tan":                floatPrimitive(math.Tan),
+		"asin":               floatPrimitive(math.Asin),
+		"acos":               floatPrimitive(math.Acos),
+		"atan":               primitiveAtan,
+		"exact":              primitiveExact,
+		"inexact":            primitiveInexact,
+		"number->string":     primitiveNumberToString,
+		"string->number":     primitiveStringToNumber,
+
+		"char?":            typePredicate(isChar),
+		"char->integer":    primitiveCharToInteger,
+		"integer->char":    primitiveIntegerToChar,
+		"char-upcase":      charMapping(unicode.ToUpper),
+		"char-downcase":    charMapping(unicode.ToLower),
+		"char-foldcase":    charMapping(unicode.ToLower),
+		"char-alphabetic?": charPredicate(unicode.IsLetter),
+		"char-numeric?":    charPredicate(unicode.IsDigit),
+		"char-whitespace?": charPredicate(unicode.IsSpace),
+		"char-upper-case?": charPredicate(unicode.IsUpper),
+		"char-lower-case?": charPredicate(unicode.IsLower),
+		"digit-value":      primitiveDigitValue,
+		"char=?":           charComparison(func(c int) bool { return c == 0 }, false),
+		"char<?":           charComparison(func(c int) bool { return c < 0 }, false),
+		"char>?":           charComparison(func(c int) bool { return c > 0 }, false),
+		"char<=?":          charComparison(func(c int) bool { return c <= 0 }, false),
+		"char>=?":          charComparison(func(c int) bool { return c >= 0 }, false),
+		"char-ci=?":        charComparison(func(c int) bool { return c == 0 }, true),
+		"char-ci<?":        charComparison(func(c int) bool { return c < 0 }, true),
+		"char-ci>?":        charComparison(func(c int) bool { return c > 0 }, true),
+		"char-ci<=?":       charComparison(func(c int) bool { return c <= 0 }, true),
+		"char-ci>=?":       charComparison(func(c int) bool { return c >= 0 }, true),
+
+		"string?":         typePredicate(isString),
+		"string":          primitiveString,
+		"make-string":     primitiveMakeString,
+		"string-length":   primitiveStringLength,
+		"string-ref":      primitiveStringRef,
+		"substring":       primitiveSubstring,
+		"string-append":   primitiveStringAppend,
+		"string->list":    primitiveStringToList,
+		"list->string":    primitiveListToString,
+		"string-upcase":   stringMapping(strings.ToUpper),
+		"string-downcase": stringMapping(strings.ToLower),
+		"string-foldcase": stringMapping(strings.ToLower),
+		"string-index":    primitiveStringIndex,
+		"string-contains": primitiveStringContains,
+		"string-split":    primitiveStringSplit,
+		"string-join":     primitiveStringJoin,
+		"string->symbol":  primitiveStringToSymbol,
+		"symbol->string":  primitiveSymbolToString,
+		"string=?":        stringComparison(func(c int) bool { return c == 0 }, false),
+		"string<?":        stringComparison(func(c int) bool { return c < 0 }, false),
+		"string>?":        stringComparison(func(c int) bool { return c > 0 }, false),
+		"string<=?":       stringComparison(func(c int) bool { return c <= 0 }, false),
+		"string>=?":       stringComparison(func(c int) bool { return c >= 0 }, false),
+		"string-ci=?":     stringComparison(func(c int) bool { return c == 0 }, true),
+		"string-ci<?":     stringComparison(func(c int) bool { return c < 0 }, true),
+		"string-ci>?":     stringComparison(func(c int) bool { return c > 0 }, true),
+		"string-ci<=?":    stringComparison(func(c int) bool { return c <= 0 }, true),
+		"string-ci>=?":    stringComparison(func(c int) bool { return c >= 0 }, true),
+
+		"vector?":         typePredicate(isVector),
+		"make-vector":     primitiveMakeVector,
+		"vector":          primitiveVector,
+		"vector-length":   primitiveVectorLength,
+		"vector-ref":      primitiveVectorRef,
+		"vector-set!":     primitiveVectorSet,
+		"vector->list":    primitiveVectorToList,
+		"list->vector":    primitiveListToVector,
+		"vector-fill!":    primitiveVectorFill,
+		"vector-map":      primitiveVectorMap,
+		"vector-for-each": primitiveVectorForEach,
+
+		"bytevector?":         typePredicate(isBytevector),
+		"make-bytevector":     primitiveMakeBytevector,
+		"bytevector":          primitiveBytevector,
+		"bytevector-length":   primitiveBytevectorLength,
+		"bytevector-u8-ref":   primitiveBytevectorU8Ref,
+		"bytevector-u8-set!":  primitiveBytevectorU8Set,
+		"bytevector-copy":     primitiveBytevectorCopy,
+		"bytevector-append":   primitiveBytevectorAppend,
+		"utf8->string":        primitiveUTF8ToString,
+		"string->utf8":        primitiveStringToUTF8,
+		"bytevector-u16-ref":  bytevectorIntRef(2, false),
+		"bytevector-s16-ref":  bytevectorIntRef(2, true),
+		"bytevector-u32-ref":  bytevectorIntRef(4, false),
+		"bytevector-s32-ref":  bytevectorIntRef(4, true),
+		"bytevector-u64-ref":  bytevectorIntRef(8, false),
+		"bytevector-s64-ref":  bytevectorIntRef(8, true),
+		"bytevector-u16-set!": bytevectorIntSet(2, false),
+		"bytevector-s16-set!": bytevectorIntSet(2, true),
+		"bytevector-u32-set!": bytevectorIntSet(4, false),
+		"bytevector-s32-set!": bytevectorIntSet(4, true),
+		"bytevector-u64-set!": bytevectorIntSet(8, false),
+		"bytevector-s64-set!": bytevectorIntSet(8, true),
+
+		"make-hash-table":        primitiveMakeHashTable,
+		"hash-table?":            typePredicate(isHashTable),
+		"hash-table-ref":         primitiveHashTableRef,
+		"hash-table-ref/default": primitiveHashTableRefDefault,
+		"hash-table-set!":        primitiveHashTableSet,
+		"hash-table-delete!":     primitiveHashTableDelete,
+		"hash-table-contains?":   primitiveHashTableContains,
+		"hash-table-count":       primitiveHashTableCount,
+		"hash-table-keys":        primitiveHashTableKeys,
+		"hash-table-values":      primitiveHashTableValues,
+		"hash-table-walk":        primitiveHashTableWalk,
+		"hash-table-update!":     primitiveHashTableUpdate,
+	}
+}
+
+var (
+	errInvalidArgumentType = errors.New("bad argument type")
+	errDivideByZero        = errors.New("divide by zero")
+	errTypeNotOrderable    = errors.New("type is not orderable")
+	errInvalidRadix        = errors.New("radix must be 2, 8, 10 or 16")
+	errIndexOutOfRange     = errors.New("index out of range")
+)
+
+func primitiveAdd(args []value) (value, error) {
+	return foldArithmetic('+', numberValue{0}, args)
+}
+
+func primitiveSubtract(args []value) (value, error) {
+	if len(args) == 0 {
+		return numberValue{0}, nil
+	}
+
+	if len(args) == 1 { // Single argument is a special case (negation)
+		return arithmetic('-', numberValue{0}, args[0])
+	}
+
+	return foldArithmetic('-', args[0], args[1:])
+}
+
+func primitiveMultiply(args []value) (value, error) {
+	return foldArithmetic('*', numberValue{1}, args)
+}
+
+func primitiveDivide(args []value) (value, error) {
+	if len(args) == 0 {
+		return numberValue{1}, nil
+	}
+
+	if len(args) == 1 { // Single argument is a special case (reciprocal)
+		return arithmetic('/', numberValue{1}, args[0])
+	}
+
+	return foldArithmetic('/', args[0], args[1:])
+}
+
+// foldArithmetic applies op to init and each of args in turn, from left to
+// right.
+func foldArithmetic(op byte, init value, args []value) (value, error) {
+	res := init
+	if !isNumber(res) {
+		return nil, errInvalidArgumentType
+	}
+
+	for _, v := range args {
+		var err error
+		if res, err = arithmetic(op, res, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// primitiveEquals reports whether its arguments, which must be numbers, are
+// all equal.
+func primitiveEquals(args []value) (value, error) {
+	if len(args) < 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkNumbers(args); err != nil {
+		return nil, err
+	}
+
+	return compareChain(args, func(c int) bool { return c == 0 })
+}
+
+// primitiveEq compares two values by identity.
+func primitiveEq(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	return boolValue{identical(args[0], args[1])}, nil
+}
+
+// primitiveEqv compares two values by identity, except that numbers, strings,
+// characters, booleans and symbols with the same value are the same.
+func primitiveEqv(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	res, err := args[0].equals(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return boolValue{res}, nil
+}
+
+func primitiveEqual(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	return boolValue{equalValues(args[0], args[1])}, nil
+}
+
+// comparisonPrimitive returns a primitive that reports whether each of its
+// arguments is ordered relative to the next as test requires of the result
+// of comparing them.
+func comparisonPrimitive(test func(c int) bool) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) < 2 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		return compareChain(args, test)
+	}
+}
+
+// compareChain compares each adjacent pair of args. All of them are compared,
+// even once the result is known, so that invalid arguments are reported.
+func compareChain(args []value, test func(c int) bool) (value, error) {
+	res := true
+
+	for i := 1; i < len(args); i++ {
+		c, ok, err := compareValues(args[i-1], args[i])
+		if err != nil {
+			return nil, err
+		}
+
+		res = res && ok && test(c)
+	}
+
+	return boolValue{res}, nil
+}
+
+// compareValues returns -1, 0 or 1 as a is less than, equal to or greater than
+// b. ok is false if they are not ordered, as NaN is not with any number.
+func compareValues(a, b value) (c int, ok bool, err error) {
+	if isNumber(a) && isNumber(b) {
+		c, ok := compareNumbers(a, b)
+		return c, ok, nil
+	}
+
+	x, ok := a.(orderable)
+	if !ok {
+		return 0, false, errTypeNotOrderable
+	}
+
+	gt, err := x.greaterThan(b)
+	if err != nil {
+		return 0, false, err
+	}
+	if gt {
+		return 1, true, nil
+	}
+
+	// Since a is greater than b without error, b is of the same type.
+	lt, err := b.(orderable).greaterThan(a)
+	if err != nil {
+		return 0, false, err
+	}
+	if lt {
+		return -1, true, nil
+	}
+
+	return 0, true, nil
+}
+
+func primitiveCons(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	return &pairValue{car: args[0], cdr: args[1]}, nil
+}
+
+func primitiveCar(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	pair, ok := args[0].(*pairValue)
+	if !ok {
+		return nil, errInvalidArgumentType
+	}
+
+	return pair.car, nil
+}
+
+func primitiveCdr(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	pair, ok := args[0].(*pairValue)
+	if !ok {
+		return nil, errInvalidArgumentType
+	}
+
+	return pair.cdr, nil
+}
+
+func primitiveSetCar(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	pair, ok := args[0].(*pairValue)
+	if !ok {
+		return nil, errInvalidArgumentType
+	}
+
+	pair.car = args[1]
+	return nullValue{}, nil
+}
+
+func primitiveSetCdr(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	pair, ok := args[0].(*pairValue)
+	if !ok {
+		return nil, errInvalidArgumentType
+	}
+
+	pair.cdr = args[1]
+	return nullValue{}, nil
+}
+
+func primitivePair(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	_, ok := args[0].(*pairValue)
+	return boolValue{ok}, nil
+}
+
+func primitiveNull(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	_, ok := args[0].(nullValue)
+	return boolValue{ok}, nil
+}
+
+func isSymbol(v value) bool {
+	_, ok := v.(*symbolValue)
+	return ok
+}
+
+// checkNumbers checks that the arguments of a numeric primitive are all
+// numbers.
+func checkNumbers(args []value) error {
+	for _, v := range args {
+		if !isNumber(v) {
+			return errs.WrapAfterf(errInvalidArgumentType, "want number, got %s", typeName(v))
+		}
+	}
+
+	return nil
+}
+
+// checkIntegers is like checkNumbers, except that the arguments must all be
+// integers.
+func checkIntegers(args []value) error {
+	if err := checkNumbers(args); err != nil {
+		return err
+	}
+
+	for _, v := range args {
+		if !isInteger(v) {
+			return errs.WrapAfterf(errInvalidArgumentType, "want integer, got %s", reprNumber(v))
+		}
+	}
+
+	return nil
+}
+
+// typePredicate returns a primitive that reports whether its argument, of any
+// type, satisfies pred.
+func typePredicate(pred func(value) bool) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) != 1 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		return boolValue{pred(args[0])}, nil
+	}
+}
+
+// numberPredicate returns a primitive that reports whether its argument, which
+// must be a number, satisfies pred.
+func numberPredicate(pred func(value) bool) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) != 1 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		if err := checkNumbers(args); err != nil {
+			return nil, err
+		}
+
+		return boolValue{pred(args[0])}, nil
+	}
+}
+
+// signPredicate returns a number predicate on the comparison of its argument
+// with zero. NaN satisfies none of them.
+func signPredicate(pred func(c int) bool) func([]value) (value, error) {
+	return numberPredicate(func(v value) bool {
+		c, ok := compareNumbers(v, numberValue{0})
+		return ok && pred(c)
+	})
+}
+
+func isRational(v value) bool {
+	return isNumber(v) && !isNaN(v) && !isInfinite(v)
+}
+
+func isExactInteger(v value) bool {
+	return isExact(v) && isInteger(v)
+}
+
+func isNaN(v value) bool {
+	f, ok := v.(floatValue)
+	return ok && math.IsNaN(f.underlying)
+}
+
+func isInfinite(v value) bool {
+	f, ok := v.(floatValue)
+	return ok && math.IsInf(f.underlying, 0)
+}
+
+// parityPrimitive returns a primitive reporting whether an integer is odd, or
+// even if odd is not set.
+func parityPrimitive(odd bool) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) != 1 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		if err := checkIntegers(args); err != nil {
+			return nil, err
+		}
+
+		return boolValue{(toInteger(args[0]).Bit(0) == 1) == odd}, nil
+	}
+}
+
+// divisionPrimitive returns a primitive applying integerDivision with op.
+func divisionPrimitive(op string) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) != 2 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		if err := checkIntegers(args); err != nil {
+			return nil, err
+		}
+
+		return integerDivision(op, args[0], args[1])
+	}
+}
+
+func primitiveAbs(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkNumbers(args); err != nil {
+		return nil, err
+	}
+
+	if f, ok := args[0].(floatValue); ok {
+		return floatValue{math.Abs(f.underlying)}, nil
+	}
+
+	if c, _ := compareNumbers(args[0], numberValue{0}); c < 0 {
+		return arithmetic('-', numberValue{0}, args[0])
+	}
+
+	return args[0], nil
+}
+
+// extremumPrimitive returns a primitive for the maximum of its arguments if
+// sign is 1, or their minimum if it is -1. The result is inexact if any
+// argument is, and NaN if any argument is.
+func extremumPrimitive(sign int) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) == 0 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		if err := checkNumbers(args); err != nil {
+			return nil, err
+		}
+
+		res, exact := args[0], isExact(args[0])
+		for _, v := range args[1:] {
+			exact = exact && isExact(v)
+
+			c, ok := compareNumbers(v, res)
+			switch {
+			case !ok:
+				res = floatValue{math.NaN()}
+			case c == sign:
+				res = v
+			}
+		}
+
+		if !exact {
+			return floatValue{toFloat(res)}, nil
+		}
+
+		return res, nil
+	}
+}
+
+func primitiveGcd(args []value) (value, error) {
+	if err := checkIntegers(args); err != nil {
+		return nil, err
+	}
+
+	res, exact := new(big.Int), true
+	for _, v := range args {
+		exact = exact && isExact(v)
+		res.GCD(nil, nil, res, new(big.Int).Abs(toInteger(v)))
+	}
+
+	return integerResult(res, exact), nil
+}
+
+func primitiveLcm(args []value) (value, error) {
+	if err := checkIntegers(args); err != nil {
+		return nil, err
+	}
+
+	res, exact := big.NewInt(1), true
+	for _, v := range args {
+		exact = exact && isExact(v)
+
+		n := new(big.Int).Abs(toInteger(v))
+		if n.Sign() == 0 {
+			res.SetInt64(0)
+			continue
+		}
+
+		if res.Sign() != 0 {
+			gcd := new(big.Int).GCD(nil, nil, res, n)
+			res.Mul(res, n.Quo(n, gcd))
+		}
+	}
+
+	return integerResult(res, exact), nil
+}
+
+// integerResult returns the integer n as an exact or inexact number.
+func integerResult(n *big.Int, exact bool) value {
+	if !exact {
+		return floatValue{toFloat(bigValue{n})}
+	}
+	return normalizeInt(n)
+}
+
+// roundingPrimitive returns a primitive applying roundNumber with mode.
+func roundingPrimitive(mode string) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) != 1 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		if err := checkNumbers(args); err != nil {
+			return nil, err
+		}
+
+		return roundNumber(mode, args[0]), nil
+	}
+}
+
+func primitiveExpt(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkNumbers(args); err != nil {
+		return nil, err
+	}
+
+	return exptNumber(args[0], args[1])
+}
+
+func primitiveSqrt(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkNumbers(args); err != nil {
+		return nil, err
+	}
+
+	return sqrtNumber(args[0]), nil
+}
+
+// primitiveExactIntegerSqrt returns the list (s r) of the integer square root s
+// of a non-negative exact integer and the remainder r, so that s*s + r is the
+// integer.
+func primitiveExactIntegerSqrt(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkIntegers(args); err != nil {
+		return nil, err
+	}
+
+	n := toInteger(args[0])
+	if !isExact(args[0]) || n.Sign() < 0 {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want non-negative exact integer, got %s", reprNumber(args[0]))
+	}
+
+	s, _ := exactSqrt(n)
+	r := new(big.Int).Sub(n, new(big.Int).Mul(s, s))
+
+	return makeList([]value{normalizeInt(s), normalizeInt(r)}), nil
+}
+
+// floatPrimitive returns a primitive applying f to its argument, as an inexact
+// number.
+func floatPrimitive(f func(float64) float64) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) != 1 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		if err := checkNumbers(args); err != nil {
+			return nil, err
+		}
+
+		return floatValue{f(toFloat(args[0]))}, nil
+	}
+}
+
+// primitiveLog returns the natural logarithm of its first argument, or its
+// logarithm in the base given by the second.
+func primitiveLog(args []value) (value, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkNumbers(args); err != nil {
+		return nil, err
+	}
+
+	res := math.Log(toFloat(args[0]))
+	if len(args) == 2 {
+		res /= math.Log(toFloat(args[1]))
+	}
+
+	return floatValue{res}, nil
+}
+
+// primitiveAtan returns the arctangent of its argument, or with two arguments
+// y and x, the angle of the point (x, y).
+func primitiveAtan(args []value) (value, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkNumbers(args); err != nil {
+		return nil, err
+	}
+
+	if len(args) == 2 {
+		return floatValue{math.Atan2(toFloat(args[0]), toFloat(args[1]))}, nil
+	}
+
+	return floatValue{math.Atan(toFloat(args[0]))}, nil
+}
+
+func primitiveExact(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkNumbers(args); err != nil {
+		return nil, err
+	}
+
+	res, ok := toExact(args[0])
+	if !ok {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "%s has no exact equivalent", reprNumber(args[0]))
+	}
+
+	return res, nil
+}
+
+func primitiveInexact(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkNumbers(args); err != nil {
+		return nil, err
+	}
+
+	return floatValue{toFloat(args[0])}, nil
+}
+
+// radixArg returns the optional radix argument of number->string and
+// string->number, which defaults to 10.
+func radixArg(args []value) (int, error) {
+	if len(args) == 0 {
+		return 10, nil
+	}
+
+	v := args[0]
+	n, ok := v.(numberValue)
+	if !ok {
+		return 0, errs.WrapAfterf(errInvalidArgumentType, "want number, got %s", typeName(v))
+	}
+
+	switch n.underlying {
+	case 2, 8, 10, 16:
+		return n.underlying, nil
+	default:
+		return 0, errs.WrapAfterf(errInvalidRadix, "got %d", n.underlying)
+	}
+}
+
+func primitiveNumberToString(args []value) (value, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkNumbers(args[:1]); err != nil {
+		return nil, err
+	}
+
+	radix, err := radixArg(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := formatNumber(args[0], radix)
+	if !ok {
+		return nil, errs.WrapAfterf(errInvalidRadix, "inexact numbers can only be written in radix 10")
+	}
+
+	return newString(s), nil
+}
+
+// primitiveStringToNumber parses a number with the same syntax as a numeric
+// literal, returning #f if the string is not one.
+func primitiveStringToNumber(args []value) (value, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	arg := args[0]
+
+	s, ok := arg.(stringValue)
+	if !ok {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want string, got %s", typeName(arg))
+	}
+
+	radix, err := radixArg(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok := parseNumber(s.underlying, radix); ok {
+		return v, nil
+	}
+
+	return boolValue{false}, nil
+}
+
+// checkChars checks that the arguments of a character primitive are all
+// characters.
+func checkChars(args []value) error {
+	for _, v := range args {
+		if _, ok := v.(charValue); !ok {
+			return errs.WrapAfterf(errInvalidArgumentType, "want char, got %s", typeName(v))
+		}
+	}
+
+	return nil
+}
+
+func isChar(v value) bool {
+	_, ok := v.(charValue)
+	return ok
+}
+
+func primitiveCharToInteger(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkChars(args); err != nil {
+		return nil, err
+	}
+
+	return numberValue{int(args[0].(charValue).underlying)}, nil
+}
+
+func primitiveIntegerToChar(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	n, ok := args[0].(numberValue)
+	if !ok || !validRune(n.underlying) {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want Unicode code point, got %s", Repr(args[0]))
+	}
+
+	return charValue{rune(n.underlying)}, nil
+}
+
+// charMapping returns a primitive applying f to its argument, a character.
+func charMapping(f func(rune) rune) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) != 1 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		if err := checkChars(args); err != nil {
+			return nil, err
+		}
+
+		return charValue{f(args[0].(charValue).underlying)}, nil
+	}
+}
+
+// charPredicate returns a primitive that reports whether its argument, which
+// must be a character, satisfies pred.
+func charPredicate(pred func(rune) bool) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) != 1 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		if err := checkChars(args); err != nil {
+			return nil, err
+		}
+
+		return boolValue{pred(args[0].(charValue).underlying)}, nil
+	}
+}
+
+// primitiveDigitValue returns the value of a decimal digit, in any script, or
+// #f if the character is not one.
+func primitiveDigitValue(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkChars(args); err != nil {
+		return nil, err
+	}
+
+	r := args[0].(charValue).underlying
+	if !unicode.IsDigit(r) {
+		return boolValue{false}, nil
+	}
+
+	// Unicode encodes each script's digits as a run from zero to nine, and
+	// adjacent runs each start at zero.
+	n := 0
+	for unicode.IsDigit(r - rune(n) - 1) {
+		n++
+	}
+
+	return numberValue{n % 10}, nil
+}
+
+// charComparison returns a primitive like comparisonPrimitive for characters,
+// which compares them ignoring case if fold is set.
+func charComparison(test func(c int) bool, fold bool) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) < 2 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		if err := checkChars(args); err != nil {
+			return nil, err
+		}
+
+		if fold {
+			folded := make([]value, len(args))
+			for i, v := range args {
+				folded[i] = charValue{unicode.ToLower(v.(charValue).underlying)}
+			}
+			args = folded
+		}
+
+		return compareChain(args, test)
+	}
+}
+
+// checkStrings checks that the arguments of a string primitive are all
+// strings.
+func checkStrings(args []value) error {
+	for _, v := range args {
+		if !isString(v) {
+			return errs.WrapAfterf(errInvalidArgumentType, "want string, got %s", typeName(v))
+		}
+	}
+
+	return nil
+}
+
+func isString(v value) bool {
+	_, ok := v.(stringValue)
+	return ok
+}
+
+// maxLength is the largest length of a string, vector or bytevector that can be
+// made with a fill, so that a mistaken length is an error rather than an
+// attempt to allocate gigabytes.
+const maxLength = 1 << 24
+
+// indexArg returns the argument v as an index, which must be at most limit.
+func indexArg(v value, limit int) (int, error) {
+	n, ok := v.(numberValue)
+	if !ok {
+		return 0, errs.WrapAfterf(errInvalidArgumentType, "want index, got %s", typeName(v))
+	}
+
+	if n.underlying < 0 || n.underlying > limit {
+		return 0, errs.WrapAfterf(errIndexOutOfRange, "%d not in [0, %d]", n.underlying, limit)
+	}
+
+	return n.underlying, nil
+}
+
+// rangeArgs returns the optional start and end arguments of a primitive
+// operating on part of a sequence of length n, which default to all of it.
+func rangeArgs(args []value, n int) (start, end int, err error) {
+	end = n
+
+	if len(args) > 0 {
+		if start, err = indexArg(args[0], n); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if len(args) > 1 {
+		if end, err = indexArg(args[1], n); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if start > end {
+		return 0, 0, errs.WrapAfterf(errIndexOutOfRange, "start %d after end %d", start, end)
+	}
+
+	return start, end, nil
+}
+
+// runeIndex converts the byte offset i in s to an offset in runes.
+func runeIndex(s string, i int) int {
+	return utf8.RuneCountInString(s[:i])
+}
+
+func primitiveString(args []value) (value, error) {
+	if err := checkChars(args); err != nil {
+		return nil, err
+	}
+
+	runes := make([]rune, len(args))
+	for i, v := range args {
+		runes[i] = v.(charValue).underlying
+	}
+
+	return newString(string(runes)), nil
+}
+
+func primitiveMakeString(args []value) (value, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	n, err := indexArg(args[0], maxLength)
+	if err != nil {
+		return nil, err
+	}
+
+	fill := ' '
+	if len(args) == 2 {
+		if err := checkChars(args[1:]); err != nil {
+			return nil, err
+		}
+		fill = args[1].(charValue).underlying
+	}
+
+	return newString(strings.Repeat(string(fill), n)), nil
+}
+
+func primitiveStringLength(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkStrings(args); err != nil {
+		return nil, err
+	}
+
+	return numberValue{utf8.RuneCountInString(args[0].(stringValue).underlying)}, nil
+}
+
+func primitiveStringRef(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkStrings(args[:1]); err != nil {
+		return nil, err
+	}
+
+	runes := []rune(args[0].(stringValue).underlying)
+
+	i, err := indexArg(args[1], len(runes)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	return charValue{runes[i]}, nil
+}
+
+// primitiveSubstring returns the characters of a string from start, up to
+// end or the end of the string.
+func primitiveSubstring(args []value) (value, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkStrings(args[:1]); err != nil {
+		return nil, err
+	}
+
+	runes := []rune(args[0].(stringValue).underlying)
+
+	start, end, err := rangeArgs(args[1:], len(runes))
+	if err != nil {
+		return nil, err
+	}
+
+	return newString(string(runes[start:end])), nil
+}
+
+func primitiveStringAppend(args []value) (value, error) {
+	if err := checkStrings(args); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	for _, v := range args {
+		b.WriteString(v.(stringValue).underlying)
+	}
+
+	return newString(b.String()), nil
+}
+
+func primitiveStringToList(args []value) (value, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkStrings(args[:1]); err != nil {
+		return nil, err
+	}
+
+	runes := []rune(args[0].(stringValue).underlying)
+
+	start, end, err := rangeArgs(args[1:], len(runes))
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make([]value, 0, end-start)
+	for _, r := range runes[start:end] {
+		vals = append(vals, charValue{r})
+	}
+
+	return makeList(vals), nil
+}
+
+func primitiveListToString(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	elems, ok := AsList(args[0])
+	if !ok {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want list, got %s", typeName(args[0]))
+	}
+
+	return primitiveString(elems)
+}
+
+// stringMapping returns a primitive applying f to its argument, a string.
+func stringMapping(f func(string) string) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) != 1 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		if err := checkStrings(args); err != nil {
+			return nil, err
+		}
+
+		return newString(f(args[0].(stringValue).underlying)), nil
+	}
+}
+
+// primitiveStringIndex returns the index of the first character of a string
+// that is the given character or satisfies the given predicate, or #f if
+// there is none.
+func primitiveStringIndex(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkStrings(args[:1]); err != nil {
+		return nil, err
+	}
+
+	match := func(r rune) (bool, error) {
+		return args[1].equals(charValue{r})
+	}
+
+	if IsProc(args[1]) {
+		match = func(r rune) (bool, error) {
+			v, err := apply(args[1], []value{charValue{r}})
+			if err != nil {
+				return false, err
+			}
+			return isTrue(v), nil
+		}
+	} else if err := checkChars(args[1:]); err != nil {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want char or procedure, got %s", typeName(args[1]))
+	}
+
+	i := 0
+	for _, r := range args[0].(stringValue).underlying {
+		ok, err := match(r)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return numberValue{i}, nil
+		}
+		i++
+	}
+
+	return boolValue{false}, nil
+}
+
+// primitiveStringContains returns the index at which the second string first
+// occurs in the first, or #f if it does not.
+func primitiveStringContains(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkStrings(args); err != nil {
+		return nil, err
+	}
+
+	s := args[0].(stringValue).underlying
+
+	i := strings.Index(s, args[1].(stringValue).underlying)
+	if i < 0 {
+		return boolValue{false}, nil
+	}
+
+	return numberValue{runeIndex(s, i)}, nil
+}
+
+// separatorArg returns the separator argument of string-split and
+// string-join, which may be a string or a character.
+func separatorArg(v value) (string, error) {
+	switch v := v.(type) {
+	case stringValue:
+		return v.underlying, nil
+	case charValue:
+		return string(v.underlying), nil
+	default:
+		return "", errs.WrapAfterf(errInvalidArgumentType, "want string or char, got %s", typeName(v))
+	}
+}
+
+// primitiveStringSplit returns the list of the parts of a string between
+// occurrences of a separator. An empty separator splits the string into its
+// characters.
+func primitiveStringSplit(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkStrings(args[:1]); err != nil {
+		return nil, err
+	}
+
+	sep, err := separatorArg(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(args[0].(stringValue).underlying, sep)
+
+	vals := make([]value, len(parts))
+	for i, p := range parts {
+		vals[i] = newString(p)
+	}
+
+	return makeList(vals), nil
+}
+
+// primitiveStringJoin concatenates a list of strings, separated by the given
+// separator or by a space.
+func primitiveStringJoin(args []value) (value, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	elems, ok := AsList(args[0])
+	if !ok {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want list, got %s", typeName(args[0]))
+	}
+
+	if err := checkStrings(elems); err != nil {
+		return nil, err
+	}
+
+	sep := " "
+	if len(args) == 2 {
+		var err error
+		if sep, err = separatorArg(args[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	parts := make([]string, len(elems))
+	for i, v := range elems {
+		parts[i] = v.(stringValue).underlying
+	}
+
+	return newString(strings.Join(parts, sep)), nil
+}
+
+func primitiveStringToSymbol(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkStrings(args); err != nil {
+		return nil, err
+	}
+
+	return intern(args[0].(stringValue).underlying), nil
+}
+
+func primitiveSymbolToString(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	sym, ok := args[0].(*symbolValue)
+	if !ok {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want symbol, got %s", typeName(args[0]))
+	}
+
+	return newString(sym.name), nil
+}
+
+// stringComparison returns a primitive like comparisonPrimitive for strings,
+// which compares them ignoring case if fold is set.
+func stringComparison(test func(c int) bool, fold bool) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) < 2 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		if err := checkStrings(args); err != nil {
+			return nil, err
+		}
+
+		if fold {
+			folded := make([]value, len(args))
+			for i, v := range args {
+				folded[i] = newString(strings.ToLower(v.(stringValue).underlying))
+			}
+			args = folded
+		}
+
+		return compareChain(args, test)
+	}
+}
+
+// checkVector checks that v, an argument of a vector primitive, is a vector.
+func checkVector(v value) (*vectorValue, error) {
+	vec, ok := v.(*vectorValue)
+	if !ok {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want vector, got %s", typeName(v))
+	}
+	return vec, nil
+}
+
+func isVector(v value) bool {
+	_, ok := v.(*vectorValue)
+	return ok
+}
+
+// primitiveMakeVector returns a vector of the given length, whose elements are
+// all the given fill value, or the empty list.
+func primitiveMakeVector(args []value) (value, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	n, err := indexArg(args[0], maxLength)
+	if err != nil {
+		return nil, err
+	}
+
+	var fill value = nullValue{}
+	if len(args) == 2 {
+		fill = args[1]
+	}
+
+	elems := make([]value, n)
+	for i := range elems {
+		elems[i] = fill
+	}
+
+	return &vectorValue{elems: elems}, nil
+}
+
+func primitiveVector(args []value) (value, error) {
+	return &vectorValue{elems: append([]value(nil), args...)}, nil
+}
+
+func primitiveVectorLength(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	vec, err := checkVector(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return numberValue{len(vec.elems)}, nil
+}
+
+func primitiveVectorRef(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	vec, err := checkVector(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := indexArg(args[1], len(vec.elems)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	return vec.elems[i], nil
+}
+
+func primitiveVectorSet(args []value) (value, error) {
+	if len(args) != 3 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	vec, err := checkVector(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := indexArg(args[1], len(vec.elems)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	vec.elems[i] = args[2]
+	return nullValue{}, nil
+}
+
+func primitiveVectorToList(args []value) (value, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	vec, err := checkVector(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := rangeArgs(args[1:], len(vec.elems))
+	if err != nil {
+		return nil, err
+	}
+
+	return makeList(vec.elems[start:end]), nil
+}
+
+func primitiveListToVector(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	elems, ok := AsList(args[0])
+	if !ok {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want list, got %s", typeName(args[0]))
+	}
+
+	return &vectorValue{elems: elems}, nil
+}
+
+// primitiveVectorFill sets the elements of a vector, from start up to end if
+// they are given, to a value.
+func primitiveVectorFill(args []value) (value, error) {
+	if len(args) < 2 || len(args) > 4 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	vec, err := checkVector(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := rangeArgs(args[2:], len(vec.elems))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := start; i < end; i++ {
+		vec.elems[i] = args[1]
+	}
+
+	return nullValue{}, nil
+}
+
+// mapVectors applies the procedure that is the first of args to the elements
+// of the vectors that follow at each index in turn, up to the length of the
+// shortest, collecting the results if collect is set.
+func mapVectors(args []value, collect bool) ([]value, error) {
+	if len(args) < 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	f := args[0]
+	if !IsProc(f) {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want procedure, got %s", typeName(f))
+	}
+
+	vecs := make([]*vectorValue, len(args)-1)
+	n := -1
+	for i, v := range args[1:] {
+		vec, err := checkVector(v)
+		if err != nil {
+			return nil, err
+		}
+		vecs[i] = vec
+
+		if n < 0 || len(vec.elems) < n {
+			n = len(vec.elems)
+		}
+	}
+
+	var res []value
+	if collect {
+		res = make([]value, 0, n)
+	}
+
+	for i := 0; i < n; i++ {
+		fargs := make([]value, len(vecs))
+		for j, vec := range vecs {
+			fargs[j] = vec.elems[i]
+		}
+
+		v, err := apply(f, fargs)
+		if err != nil {
+			return nil, err
+		}
+
+		if collect {
+			res = append(res, v)
+		}
+	}
+
+	return res, nil
+}
+
+func primitiveVectorMap(args []value) (value, error) {
+	res, err := mapVectors(args, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vectorValue{elems: res}, nil
+}
+
+func primitiveVectorForEach(args []value) (value, error) {
+	if _, err := mapVectors(args, false); err != nil {
+		return nil, err
+	}
+
+	return nullValue{}, nil
+}
+
+// checkHashTable checks that v, an argument of a hash table primitive, is a
+// hash table.
+func checkHashTable(v value) (*hashTableValue, error) {
+	t, ok := v.(*hashTableValue)
+	if !ok {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want hash table, got %s", typeName(v))
+	}
+	return t, nil
+}
+
+func isHashTable(v value) bool {
+	_, ok := v.(*hashTableValue)
+	return ok
+}
+
+// primitiveMakeHashTable returns an empty hash table, whose keys are compared
+// with the given equivalence procedure: equal?, the default, or eqv? or eq?.
+func primitiveMakeHashTable(args []value) (value, error) {
+	if len(args) > 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if len(args) == 0 {
+		return newHashTable(equalValues, true), nil
+	}
+
+	if b, ok := args[0].(*builtinValue); ok {
+		switch b.name {
+		case "equal?":
+			return newHashTable(equalValues, true), nil
+		case "eqv?":
+			return newHashTable(eqvValues, false), nil
+		case "eq?":
+			return newHashTable(identical, false), nil
+		}
+	}
+
+	return nil, errs.WrapAfterf(errInvalidArgumentType, "want equal?, eqv? or eq?, got %s", Repr(args[0]))
+}
+
+// primitiveHashTableRef returns the value for a key, or if there is none, the
+// result of calling the given thunk.
+func primitiveHashTableRef(args []value) (value, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	t, err := checkHashTable(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return hashTableRef(t, args[1], args[2:])
+}
+
+// hashTableRef returns the value for key in t, or the result of calling the
+// thunk in missing, if any, when there is none.
+func hashTableRef(t *hashTableValue, key value, missing []value) (value, error) {
+	if e := t.lookup(key); e != nil {
+		return e.val, nil
+	}
+
+	if len(missing) == 0 {
+		return nil, errs.WrapAfterf(errKeyNotFound, "%s", Repr(key))
+	}
+
+	return apply(missing[0], nil)
+}
+
+func primitiveHashTableRefDefault(args []value) (value, error) {
+	if len(args) != 3 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	t, err := checkHashTable(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if e := t.lookup(args[1]); e != nil {
+		return e.val, nil
+	}
+
+	return args[2], nil
+}
+
+func primitiveHashTableSet(args []value) (value, error) {
+	if len(args) != 3 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	t, err := checkHashTable(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	t.set(args[1], args[2])
+	return nullValue{}, nil
+}
+
+func primitiveHashTableDelete(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	t, err := checkHashTable(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	t.delete(args[1])
+	return nullValue{}, nil
+}
+
+func primitiveHashTableContains(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	t, err := checkHashTable(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return boolValue{t.lookup(args[1]) != nil}, nil
+}
+
+func primitiveHashTableCount(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	t, err := checkHashTable(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return numberValue{t.count}, nil
+}
+
+// primitiveHashTableKeys returns the list of the keys of a hash table, in the
+// order they were added.
+func primitiveHashTableKeys(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	t, err := checkHashTable(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []value
+	for _, e := range t.entries() {
+		keys = append(keys, e.key)
+	}
+
+	return makeList(keys), nil
+}
+
+// primitiveHashTableValues returns the list of the values of a hash table, in
+// the same order as hash-table-keys.
+func primitiveHashTableValues(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	t, err := checkHashTable(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var vals []value
+	for _, e := range t.entries() {
+		vals = append(vals, e.val)
+	}
+
+	return makeList(vals), nil
+}
+
+// primitiveHashTableWalk calls a procedure with each key of a hash table and
+// its value. The entries walked are those present when the walk starts.
+func primitiveHashTableWalk(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	t, err := checkHashTable(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsProc(args[1]) {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want procedure, got %s", typeName(args[1]))
+	}
+
+	for _, e := range t.entries() {
+		if _, err := apply(args[1], []value{e.key, e.val}); err != nil {
+			return nil, err
+		}
+	}
+
+	return nullValue{}, nil
+}
+
+// primitiveHashTableUpdate sets the value for a key to the result of calling a
+// procedure with its current value, which is found as by hash-table-ref.
+func primitiveHashTableUpdate(args []value) (value, error) {
+	if len(args) != 3 && len(args) != 4 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	t, err := checkHashTable(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsProc(args[2]) {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want procedure, got %s", typeName(args[2]))
+	}
+
+	v, err := hashTableRef(t, args[1], args[3:])
+	if err != nil {
+		return nil, err
+	}
+
+	if v, err = apply(args[2], []value{v}); err != nil {
+		return nil, err
+	}
+
+	t.set(args[1], v)
+	return nullValue{}, nil
+}
+
+// checkBytevector checks that v, an argument of a bytevector primitive, is a
+// bytevector.
+func checkBytevector(v value) (*bytevectorValue, error) {
+	b, ok := v.(*bytevectorValue)
+	if !ok {
+		return nil, errs.WrapAfterf(errInvalidArgumentType, "want bytevector, got %s", typeName(v))
+	}
+	return b, nil
+}
+
+func isBytevector(v value) bool {
+	_, ok := v.(*bytevectorValue)
+	return ok
+}
+
+// byteArg returns the argument v as a byte.
+func byteArg(v value) (byte, error) {
+	b, ok := byteOf(v)
+	if !ok {
+		return 0, errs.WrapAfterf(errInvalidArgumentType, "want byte, got %s", Repr(v))
+	}
+	return b, nil
+}
+
+// primitiveMakeBytevector returns a bytevector of the given length, whose
+// bytes are all the given fill value, or 0.
+func primitiveMakeBytevector(args []value) (value, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	n, err := indexArg(args[0], maxLength)
+	if err != nil {
+		return nil, err
+	}
+
+	var fill byte
+	if len(args) == 2 {
+		if fill, err = byteArg(args[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = fill
+	}
+
+	return &bytevectorValue{bytes: b}, nil
+}
+
+func primitiveBytevector(args []value) (value, error) {
+	b := make([]byte, len(args))
+	for i, v := range args {
+		var err error
+		if b[i], err = byteArg(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return &bytevectorValue{bytes: b}, nil
+}
+
+func primitiveBytevectorLength(args []value) (value, error) {
+	if len(args) != 1 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	b, err := checkBytevector(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return numberValue{len(b.bytes)}, nil
+}
+
+func primitiveBytevectorU8Ref(args []value) (value, error) {
+	if len(args) != 2 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	b, err := checkBytevector(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := indexArg(args[1], len(b.bytes)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	return numberValue{int(b.bytes[i])}, nil
+}
+
+func primitiveBytevectorU8Set(args []value) (value, error) {
+	if len(args) != 3 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	b, err := checkBytevector(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := indexArg(args[1], len(b.bytes)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := byteArg(args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	b.bytes[i] = c
+	return nullValue{}, nil
+}
+
+// primitiveBytevectorCopy returns a new bytevector holding the bytes of a
+// bytevector from start, up to end or the end of the bytevector.
+func primitiveBytevectorCopy(args []value) (value, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	b, err := checkBytevector(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := rangeArgs(args[1:], len(b.bytes))
+	if err != nil {
+		return nil, err
+	}
+
+	return &bytevectorValue{bytes: append([]byte{}, b.bytes[start:end]...)}, nil
+}
+
+func primitiveBytevectorAppend(args []value) (value, error) {
+	res := []byte{}
+	for _, v := range args {
+		b, err := checkBytevector(v)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, b.bytes...)
+	}
+
+	return &bytevectorValue{bytes: res}, nil
+}
+
+// primitiveUTF8ToString decodes the bytes of a bytevector from start, up to
+// end or the end of the bytevector, as UTF-8. Invalid sequences are decoded
+// as the replacement character.
+func primitiveUTF8ToString(args []value) (value, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	b, err := checkBytevector(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := rangeArgs(args[1:], len(b.bytes))
+	if err != nil {
+		return nil, err
+	}
+
+	return newString(strings.ToValidUTF8(string(b.bytes[start:end]), "\uFFFD")), nil
+}
+
+// primitiveStringToUTF8 encodes the characters of a string from start, up to
+// end or the end of the string, as UTF-8.
+func primitiveStringToUTF8(args []value) (value, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, errWrongNumberOfArguments
+	}
+
+	if err := checkStrings(args[:1]); err != nil {
+		return nil, err
+	}
+
+	runes := []rune(args[0].(stringValue).underlying)
+
+	start, end, err := rangeArgs(args[1:], len(runes))
+	if err != nil {
+		return nil, err
+	}
+
+	return &bytevectorValue{bytes: []byte(string(runes[start:end]))}, nil
+}
+
+// endiannessArg returns the byte order named by the argument v, the symbol
+// little or big.
+func endiannessArg(v value) (binary.ByteOrder, error) {
+	if s, ok := v.(*symbolValue); ok {
+		switch s.name {
+		case "little":
+			return binary.LittleEndian, nil
+		case "big":
+			return binary.BigEndian, nil
+		}
+	}
+
+	return nil, errs.WrapAfterf(errInvalidArgumentType, "want little or big, got %s", Repr(v))
+}
+
+// bytevectorIntRef returns a primitive that reads the integer of size bytes,
+// signed or not, at an index of a bytevector in the given byte order.
+func bytevectorIntRef(size int, signed bool) func([]value) (value, error) {
+	return func(args []value) (value, error) {
+		if len(args) != 3 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		b, err := checkBytevector(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		i, err := indexArg(args[1], len(b.bytes)-size)
+		if err != nil {
+			return nil, err
+		}
+
+		order, err := endiannessArg(args[2])
+		if err != nil {
+			return nil, err
+		}
+
+		var u uint64
+		switch size {
+		case 2:
+			u = uint64(order.Uint16(b.bytes[i:]))
+		case 4:
+			u = uint64(order.Uint32(b.bytes[i:]))
+		default:
+			u = order.Uint64(b.bytes[i:])
+		}
+
+		if signed {
+			shift := 64 - 8*size
+			return normalizeInt(big.NewInt(int64(u<<shift) >> shift)), nil
+		}
+
+		return normalizeInt(new(big.Int).SetUint64(u)), nil
+	}
+}
+
+// bytevectorIntSet returns a primitive that writes an integer as size bytes,
+// signed or not, at an index of a bytevector in the given byte order.
+func bytevectorIntSet(size int, signed bool) func([]value) (value, error) {
+	bits := uint(8 * size)
+
+	// lo and hi are the bounds of the integers that fit.
+	lo, hi := new(big.Int), new(big.Int).Lsh(big.NewInt(1), bits)
+	kind := "unsigned"
+	if signed {
+		lo.Neg(new(big.Int).Lsh(big.NewInt(1), bits-1))
+		hi.Lsh(big.NewInt(1), bits-1)
+		kind = "signed"
+	}
+	hi.Sub(hi, big.NewInt(1))
+
+	return func(args []value) (value, error) {
+		if len(args) != 4 {
+			return nil, errWrongNumberOfArguments
+		}
+
+		b, err := checkBytevector(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		i, err := indexArg(args[1], len(b.bytes)-size)
+		if err != nil {
+			return nil, err
+		}
+
+		var n *big.Int
+		switch v := args[2].(type) {
+		case numberValue, bigValue:
+			n = toInteger(v)
+		}
+		if n == nil || n.Cmp(lo) < 0 || n.Cmp(hi) > 0 {
+			return nil, errs.WrapAfterf(errInvalidArgumentType, "want %d-bit %s integer, got %s", bits, kind, Repr(args[2]))
+		}
+
+		order, err := endiannessArg(args[3])
+		if err != nil {
+			return nil, err
+		}
+
+		u := n.Uint64()
+		if signed {
+			u = uint64(n.Int64())
+		}
+
+		switch size {
+		case 2:
+			order.PutUint16(b.bytes[i:], uint16(u))
+		case 4:
+			order.PutUint32(b.bytes[i:], uint32(u))
+		default:
+			order.PutUint64(b.bytes[i:], u)
+		}
+
+		return nullValue{}, nil
+	}
+}
@@ -1,8 +1,10 @@
-package main
+package scheme
 
 import (
 	"reflect"
 	"testing"
+
+	"scgeme/errs"
 )
 
 func TestPrimitiveAdd(t *testing.T) {
@@ -40,12 +42,17 @@ func TestPrimitiveAdd(t *testing.T) {
 	for i, c := range cases {
 		t.Logf("Case %d: %s", i, c.src)
 
-		exprs, err := parse(tokenize(c.src))
+		exprs, err := parseSource("", c.src)
 		if err != nil {
 			t.Fatal("parse error", err)
 		}
 
-		got, gotErr := primitiveAdd(mustExpressionChildren(exprs[0]), newFrame())
+		args, err := mapEval(mustExpressionChildren(exprs[0]), newFrame())
+		if err != nil {
+			t.Fatal("eval error", err)
+		}
+
+		got, gotErr := primitiveAdd(args)
 
 		if gotErr == nil {
 			if !reflect.DeepEqual(got, c.want) {
@@ -53,7 +60,7 @@ func TestPrimitiveAdd(t *testing.T) {
 			}
 		}
 
-		if gotErr != c.wantErr {
+		if errs.Root(gotErr) != c.wantErr {
 			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
 		}
 	}
@@ -94,12 +101,17 @@ func TestPrimitiveSubtract(t *testing.T) {
 	for i, c := range cases {
 		t.Logf("Case %d: %s", i, c.src)
 
-		exprs, err := parse(tokenize(c.src))
+		exprs, err := parseSource("", c.src)
 		if err != nil {
 			t.Fatal("parse error", err)
 		}
 
-		got, gotErr := primitiveSubtract(mustExpressionChildren(exprs[0]), newFrame())
+		args, err := mapEval(mustExpressionChildren(exprs[0]), newFrame())
+		if err != nil {
+			t.Fatal("eval error", err)
+		}
+
+		got, gotErr := primitiveSubtract(args)
 
 		if gotErr == nil {
 			if !reflect.DeepEqual(got, c.want) {
@@ -107,7 +119,7 @@ func TestPrimitiveSubtract(t *testing.T) {
 			}
 		}
 
-		if gotErr != c.wantErr {
+		if errs.Root(gotErr) != c.wantErr {
 			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
 		}
 	}
@@ -148,12 +160,17 @@ func TestPrimitiveMultiply(t *testing.T) {
 	for i, c := range cases {
 		t.Logf("Case %d: %s", i, c.src)
 
-		exprs, err := parse(tokenize(c.src))
+		exprs, err := parseSource("", c.src)
 		if err != nil {
 			t.Fatal("parse error", err)
 		}
 
-		got, gotErr := primitiveMultiply(mustExpressionChildren(exprs[0]), newFrame())
+		args, err := mapEval(mustExpressionChildren(exprs[0]), newFrame())
+		if err != nil {
+			t.Fatal("eval error", err)
+		}
+
+		got, gotErr := primitiveMultiply(args)
 
 		if gotErr == nil {
 			if !reflect.DeepEqual(got, c.want) {
@@ -161,7 +178,7 @@ func TestPrimitiveMultiply(t *testing.T) {
 			}
 		}
 
-		if gotErr != c.wantErr {
+		if errs.Root(gotErr) != c.wantErr {
 			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
 		}
 	}
@@ -170,40 +187,56 @@ func TestPrimitiveMultiply(t *testing.T) {
 func TestPrimitiveDivide(t *testing.T) {
 	cases := []struct {
 		src     string
-		want    numberValue
+		want    string
 		wantErr error
 	}{
 		{
 			src:  `()`,
-			want: numberValue{1},
+			want: `1`,
 		},
 		{
 			src:  `(1)`,
-			want: numberValue{1},
+			want: `1`,
+		},
+		{
+			src:  `(2)`,
+			want: `1/2`,
 		},
 		{
 			src:  `(1 2)`,
-			want: numberValue{0},
+			want: `1/2`,
 		},
 		{
 			src:  `(4 2)`,
-			want: numberValue{2},
+			want: `2`,
 		},
 		{
 			src:  `(5 2)`,
-			want: numberValue{2},
+			want: `5/2`,
 		},
 		{
 			src:  `(0 2)`,
-			want: numberValue{0},
+			want: `0`,
 		},
 		{
 			src:  `(12 2 3)`,
-			want: numberValue{2},
+			want: `2`,
 		},
 		{
 			src:  `((primitive / 12 2) 3)`,
-			want: numberValue{2},
+			want: `2`,
+		},
+		{
+			src:  `((primitive / 1 3) (primitive / 1 6))`,
+			want: `2`,
+		},
+		{
+			src:  `(1 2.0)`,
+			want: `0.5`,
+		},
+		{
+			src:  `(1.0 0)`,
+			want: `+inf.0`,
 		},
 		{
 			src:     `(1 0)`,
@@ -218,20 +251,25 @@ func TestPrimitiveDivide(t *testing.T) {
 	for i, c := range cases {
 		t.Logf("Case %d: %s", i, c.src)
 
-		exprs, err := parse(tokenize(c.src))
+		exprs, err := parseSource("", c.src)
 		if err != nil {
 			t.Fatal("parse error", err)
 		}
 
-		got, gotErr := primitiveDivide(mustExpressionChildren(exprs[0]), newFrame())
+		args, err := mapEval(mustExpressionChildren(exprs[0]), newFrame())
+		if err != nil {
+			t.Fatal("eval error", err)
+		}
+
+		got, gotErr := primitiveDivide(args)
 
 		if gotErr == nil {
-			if !reflect.DeepEqual(got, c.want) {
-				t.Errorf("got:  %v\nwant: %v", got, c.want)
+			if Repr(got) != c.want {
+				t.Errorf("got:  %v\nwant: %v", Repr(got), c.want)
 			}
 		}
 
-		if gotErr != c.wantErr {
+		if errs.Root(gotErr) != c.wantErr {
 			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
 		}
 	}
@@ -256,8 +294,16 @@ func TestPrimitiveEqual(t *testing.T) {
 			wantErr: errWrongNumberOfArguments,
 		},
 		{
-			src:     `(1 2 3)`,
-			wantErr: errWrongNumberOfArguments,
+			src:  `(1 1 1)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(1 1 2)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(1 1.0 2/2)`,
+			want: boolValue{true},
 		},
 		{
 			src:  `(1 1)`,
@@ -271,17 +317,30 @@ func TestPrimitiveEqual(t *testing.T) {
 			src:  `(1 (primitive / 2 2))`,
 			want: boolValue{true},
 		},
+		{
+			src:     `(#t #t)`,
+			wantErr: errInvalidArgumentType,
+		},
+		{
+			src:     `(1 "1")`,
+			wantErr: errInvalidArgumentType,
+		},
 	}
 
 	for i, c := range cases {
 		t.Logf("Case %d: %s", i, c.src)
 
-		exprs, err := parse(tokenize(c.src))
+		exprs, err := parseSource("", c.src)
 		if err != nil {
 			t.Fatal("parse error", err)
 		}
 
-		got, gotErr := primitiveEquals(mustExpressionChildren(exprs[0]), newFrame())
+		args, err := mapEval(mustExpressionChildren(exprs[0]), newFrame())
+		if err != nil {
+			t.Fatal("eval error", err)
+		}
+
+		got, gotErr := primitiveEquals(args)
 
 		if gotErr == nil {
 			if !reflect.DeepEqual(got, c.want) {
@@ -289,7 +348,7 @@ func TestPrimitiveEqual(t *testing.T) {
 			}
 		}
 
-		if gotErr != c.wantErr {
+		if errs.Root(gotErr) != c.wantErr {
 			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
 		}
 	}
@@ -314,8 +373,20 @@ func TestPrimitiveGreaterThan(t *testing.T) {
 			wantErr: errWrongNumberOfArguments,
 		},
 		{
-			src:     `(1 2 3)`,
-			wantErr: errWrongNumberOfArguments,
+			src:  `(3 2 1)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(3 1 2)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(3 3 1)`,
+			want: boolValue{false},
+		},
+		{
+			src:     `(2 1 #t)`,
+			wantErr: errIncomparableValueTypes,
 		},
 		{
 			src:  `(1 1)`,
@@ -346,12 +417,17 @@ func TestPrimitiveGreaterThan(t *testing.T) {
 	for i, c := range cases {
 		t.Logf("Case %d: %s", i, c.src)
 
-		exprs, err := parse(tokenize(c.src))
+		exprs, err := parseSource("", c.src)
 		if err != nil {
 			t.Fatal("parse error", err)
 		}
 
-		got, gotErr := primitiveGreaterThan(mustExpressionChildren(exprs[0]), newFrame())
+		args, err := mapEval(mustExpressionChildren(exprs[0]), newFrame())
+		if err != nil {
+			t.Fatal("eval error", err)
+		}
+
+		got, gotErr := primitives[">"](args)
 
 		if gotErr == nil {
 			if !reflect.DeepEqual(got, c.want) {
@@ -359,7 +435,7 @@ func TestPrimitiveGreaterThan(t *testing.T) {
 			}
 		}
 
-		if gotErr != c.wantErr {
+		if errs.Root(gotErr) != c.wantErr {
 			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
 		}
 	}
@@ -373,14 +449,14 @@ func TestPrimitiveCons(t *testing.T) {
 	}{
 		{
 			src: `(1 2)`,
-			want: pairValue{
+			want: &pairValue{
 				car: numberValue{1},
 				cdr: numberValue{2},
 			},
 		},
 		{
 			src: `(1 (primitive + 2 3))`,
-			want: pairValue{
+			want: &pairValue{
 				car: numberValue{1},
 				cdr: numberValue{5},
 			},
@@ -402,16 +478,21 @@ func TestPrimitiveCons(t *testing.T) {
 	for i, c := range cases {
 		t.Logf("Case %d: %s", i, c.src)
 
-		exprs, err := parse(tokenize(c.src))
+		exprs, err := parseSource("", c.src)
 		if err != nil {
 			t.Fatal("parse error", err)
 		}
 
-		got, gotErr := primitiveCons(mustExpressionChildren(exprs[0]), newFrame())
+		args, err := mapEval(mustExpressionChildren(exprs[0]), newFrame())
+		if err != nil {
+			t.Fatal("eval error", err)
+		}
+
+		got, gotErr := primitiveCons(args)
 		if !reflect.DeepEqual(got, c.want) {
 			t.Errorf("got:  %v\nwant: %v", got, c.want)
 		}
-		if gotErr != c.wantErr {
+		if errs.Root(gotErr) != c.wantErr {
 			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
 		}
 	}
@@ -440,16 +521,21 @@ func TestPrimitiveCar(t *testing.T) {
 	for i, c := range cases {
 		t.Logf("Case %d: %s", i, c.src)
 
-		exprs, err := parse(tokenize(c.src))
+		exprs, err := parseSource("", c.src)
 		if err != nil {
 			t.Fatal("parse error", err)
 		}
 
-		got, gotErr := primitiveCar(mustExpressionChildren(exprs[0]), newFrame())
+		args, err := mapEval(mustExpressionChildren(exprs[0]), newFrame())
+		if err != nil {
+			t.Fatal("eval error", err)
+		}
+
+		got, gotErr := primitiveCar(args)
 		if !reflect.DeepEqual(got, c.want) {
 			t.Errorf("got:  %v\nwant: %v", got, c.want)
 		}
-		if gotErr != c.wantErr {
+		if errs.Root(gotErr) != c.wantErr {
 			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
 		}
 	}
@@ -478,17 +564,80 @@ func TestPrimitiveCdr(t *testing.T) {
 	for i, c := range cases {
 		t.Logf("Case %d: %s", i, c.src)
 
-		exprs, err := parse(tokenize(c.src))
+		exprs, err := parseSource("", c.src)
 		if err != nil {
 			t.Fatal("parse error", err)
 		}
 
-		got, gotErr := primitiveCdr(mustExpressionChildren(exprs[0]), newFrame())
+		args, err := mapEval(mustExpressionChildren(exprs[0]), newFrame())
+		if err != nil {
+			t.Fatal("eval error", err)
+		}
+
+		got, gotErr := primitiveCdr(args)
 		if !reflect.DeepEqual(got, c.want) {
 			t.Errorf("got:  %v\nwant: %v", got, c.want)
 		}
-		if gotErr != c.wantErr {
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+		}
+	}
+}
+
+func TestStringLibrary(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{src: `(list (string? "a") (string? #\a) (string? 'a))`, want: `(#t #f #f)`},
+		{src: `(list (string #\a #\b) (string) (make-string 3 #\x) (make-string 2))`, want: `("ab" "" "xxx" "  ")`},
+		{src: `(list (string-length "") (string-length "abc") (string-length "λx"))`, want: `(0 3 2)`},
+		{src: `(list (string-ref "abc" 0) (string-ref "λx" 1))`, want: `(#\a #\x)`},
+		{src: `(list (substring "hello" 1 3) (substring "hello" 2) (substring "λμν" 1 2) (substring "abc" 3))`, want: `("el" "llo" "μ" "")`},
+		{src: `(list (string-append) (string-append "a") (string-append "a" "bc" "" "d"))`, want: `("" "a" "abcd")`},
+		{src: `(list (string->list "abc") (string->list "abc" 1) (string->list "abc" 1 2))`, want: `((#\a #\b #\c) (#\b #\c) (#\b))`},
+		{src: `(list->string (list #\a #\λ))`, want: `"aλ"`},
+		{src: `(list (string-upcase "Hello") (string-downcase "Hello") (string-foldcase "ΛA"))`, want: `("HELLO" "hello" "λa")`},
+		{src: `(list (string-index "hello" #\l) (string-index "λμν" #\ν) (string-index "hello" #\z))`, want: `(2 2 #f)`},
+		{src: `(string-index "ab1c" char-numeric?)`, want: `2`},
+		{src: `(list (string-contains "hello" "ll") (string-contains "λμν" "ν") (string-contains "hello" "") (string-contains "hello" "z"))`, want: `(2 2 0 #f)`},
+		{src: `(list (string-split "a,b,,c" #\,) (string-split "a::b" "::") (string-split "" #\,))`, want: `(("a" "b" "" "c") ("a" "b") (""))`},
+		{src: `(list (string-join '("a" "b" "c")) (string-join '("a" "b") ", ") (string-join '() "-") (string-join '("a" "b") #\/))`, want: `("a b c" "a, b" "" "a/b")`},
+		{src: `(list (string->symbol "foo") (eq? (string->symbol "foo") 'foo) (symbol->string 'bar))`, want: `(foo #t "bar")`},
+		{src: `(list (string=? "a" "a" "a") (string<? "a" "b" "c") (string<? "a" "c" "b") (string>? "b" "a"))`, want: `(#t #t #f #t)`},
+		{src: `(list (string<=? "a" "a") (string>=? "a" "b") (string<? "abc" "abd") (string<? "ab" "abc"))`, want: `(#t #f #t #t)`},
+		{src: `(list (string-ci=? "aBc" "AbC") (string-ci<? "a" "B") (string<? "a" "B"))`, want: `(#t #t #f)`},
+		{src: `(list (< "a" "b") (> "b" "a" "A") (<= "a" "a"))`, want: `(#t #t #t)`},
+		{src: `(string-ref "abc" 3)`, wantErr: errIndexOutOfRange},
+		{src: `(string-ref "abc" -1)`, wantErr: errIndexOutOfRange},
+		{src: `(make-string 100000000 #\x)`, wantErr: errIndexOutOfRange},
+		{src: `(substring "abc" 2 1)`, wantErr: errIndexOutOfRange},
+		{src: `(substring "abc" 0 4)`, wantErr: errIndexOutOfRange},
+		{src: `(string-length 'abc)`, wantErr: errInvalidArgumentType},
+		{src: `(string-append "a" #\b)`, wantErr: errInvalidArgumentType},
+		{src: `(list->string (list #\a "b"))`, wantErr: errInvalidArgumentType},
+		{src: `(string-index "abc" 1)`, wantErr: errInvalidArgumentType},
+		{src: `(string-join '("a" 1))`, wantErr: errInvalidArgumentType},
+		{src: `(symbol->string "a")`, wantErr: errInvalidArgumentType},
+		{src: `(string<? "a" 'b)`, wantErr: errInvalidArgumentType},
+		{src: `(< "a" 1)`, wantErr: errIncomparableValueTypes},
+	}
+
+	in := NewInterpreter()
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := in.Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
 			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
 		}
 	}
 }
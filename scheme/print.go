@@ -0,0 +1,194 @@
+package scheme
+
+import (
+	"fmt"
+	"strings"
+)
+
+var unescapes = map[rune]string{
+	'"':  `\"`,
+	'\\': `\\`,
+	'\a': `\a`,
+	'\b': `\b`,
+	'\f': `\f`,
+	'\n': `\n`,
+	'\r': `\r`,
+	'\t': `\t`,
+	'\v': `\v`,
+}
+
+// Repr returns the external representation of a value, in a form that would
+// read back as the same value where possible.
+func Repr(v value) string {
+	switch v := v.(type) {
+	case nullValue:
+		return "()"
+	case numberValue, bigValue, ratValue, floatValue:
+		return reprNumber(v)
+	case boolValue:
+		if v.underlying {
+			return "#t"
+		}
+		return "#f"
+	case stringValue:
+		return reprString(v.underlying)
+	case charValue:
+		return reprChar(v.underlying)
+	case *symbolValue:
+		return v.name
+	case *pairValue, *vectorValue:
+		return reprCompound(v)
+	case *bytevectorValue:
+		return reprBytevector(v.bytes)
+	case *procValue:
+		if v.name == "" {
+			return "#<procedure>"
+		}
+		return "#<procedure " + v.name + ">"
+	case *builtinValue:
+		return "#<procedure " + v.name + ">"
+	case *macroValue:
+		return "#<syntax " + v.name + ">"
+	case *procMacroValue:
+		return "#<macro " + v.name + ">"
+	case *hashTableValue:
+		return fmt.Sprintf("#<hash-table %d>", v.count)
+	default:
+		return "#<unknown>"
+	}
+}
+
+func reprString(s string) string {
+	var b strings.Builder
+
+	b.WriteByte('"')
+	for _, r := range s {
+		if e, ok := unescapes[r]; ok {
+			b.WriteString(e)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+// reprCompound returns the representation of a list or vector. Pairs and
+// vectors that are part of a cycle are written with datum labels, as in
+// #0=(1 . #0#), so that printing terminates.
+func reprCompound(v value) string {
+	w := &listWriter{labels: make(map[value]int)}
+	findCycles(v, make(map[value]bool), w.labels)
+
+	w.write(v)
+	return w.b.String()
+}
+
+type listWriter struct {
+	b      strings.Builder
+	labels map[value]int // label numbers, or -1 if not yet written
+	next   int
+}
+
+func (w *listWriter) write(v value) {
+	switch v.(type) {
+	case *pairValue, *vectorValue:
+	default:
+		w.b.WriteString(Repr(v))
+		return
+	}
+
+	if n, ok := w.labels[v]; ok {
+		if n >= 0 {
+			fmt.Fprintf(&w.b, "#%d#", n)
+			return
+		}
+
+		w.labels[v] = w.next
+		fmt.Fprintf(&w.b, "#%d=", w.next)
+		w.next++
+	}
+
+	if vec, ok := v.(*vectorValue); ok {
+		w.b.WriteString("#(")
+		for i, e := range vec.elems {
+			if i > 0 {
+				w.b.WriteByte(' ')
+			}
+			w.write(e)
+		}
+		w.b.WriteByte(')')
+		return
+	}
+
+	p := v.(*pairValue)
+
+	w.b.WriteByte('(')
+	w.write(p.car)
+
+	for {
+		next, ok := p.cdr.(*pairValue)
+		if _, labelled := w.labels[next]; !ok || labelled {
+			break
+		}
+
+		w.b.WriteByte(' ')
+		w.write(next.car)
+		p = next
+	}
+
+	if _, ok := p.cdr.(nullValue); !ok {
+		w.b.WriteString(" . ")
+		w.write(p.cdr)
+	}
+
+	w.b.WriteByte(')')
+}
+
+// findCycles records in labels the pairs and vectors reachable from v that are
+// reachable from themselves. active holds those currently being traversed,
+// true while their descendants are being visited.
+func findCycles(v value, active map[value]bool, labels map[value]int) {
+	if vec, ok := v.(*vectorValue); ok {
+		if visiting, seen := active[vec]; seen {
+			if visiting {
+				labels[vec] = -1
+			}
+			return
+		}
+
+		active[vec] = true
+		for _, e := range vec.elems {
+			findCycles(e, active, labels)
+		}
+		active[vec] = false
+		return
+	}
+
+	var spine []*pairValue
+
+	for {
+		p, ok := v.(*pairValue)
+		if !ok {
+			break
+		}
+
+		if visiting, seen := active[p]; seen {
+			if visiting {
+				labels[p] = -1
+			}
+			break
+		}
+
+		active[p] = true
+		spine = append(spine, p)
+
+		findCycles(p.car, active, labels)
+		v = p.cdr
+	}
+
+	for _, p := range spine {
+		active[p] = false
+	}
+}
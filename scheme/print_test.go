@@ -0,0 +1,131 @@
+package scheme
+
+import "testing"
+
+func TestRepr(t *testing.T) {
+	cases := []struct {
+		v    value
+		want string
+	}{
+		{
+			v:    nullValue{},
+			want: "()",
+		},
+		{
+			v:    numberValue{-12},
+			want: "-12",
+		},
+		{
+			v:    boolValue{true},
+			want: "#t",
+		},
+		{
+			v:    boolValue{false},
+			want: "#f",
+		},
+		{
+			v:    newString("foo"),
+			want: `"foo"`,
+		},
+		{
+			v:    newString("foo \"bar\"\n\\"),
+			want: `"foo \"bar\"\n\\"`,
+		},
+		{
+			v:    charValue{'a'},
+			want: `#\a`,
+		},
+		{
+			v:    charValue{' '},
+			want: `#\space`,
+		},
+		{
+			v:    charValue{0x7f},
+			want: `#\delete`,
+		},
+		{
+			v:    charValue{0x200b},
+			want: `#\x200b`,
+		},
+		{
+			v:    &pairValue{car: numberValue{1}, cdr: numberValue{2}},
+			want: "(1 . 2)",
+		},
+		{
+			v:    makeList([]value{numberValue{1}, newString("a"), boolValue{true}}),
+			want: `(1 "a" #t)`,
+		},
+		{
+			v: &pairValue{
+				car: numberValue{1},
+				cdr: &pairValue{car: numberValue{2}, cdr: numberValue{3}},
+			},
+			want: "(1 2 . 3)",
+		},
+		{
+			v:    makeList([]value{makeList([]value{numberValue{1}}), nullValue{}}),
+			want: "((1) ())",
+		},
+		{
+			v:    makeList([]value{intern("quote"), intern("a-b")}),
+			want: "(quote a-b)",
+		},
+		{
+			v:    new(procValue),
+			want: "#<procedure>",
+		},
+		{
+			v:    &builtinValue{name: "foo"},
+			want: "#<procedure foo>",
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.v)
+
+		if got := Repr(c.v); got != c.want {
+			t.Errorf("got:  %v\nwant: %v", got, c.want)
+		}
+	}
+}
+
+func TestReprCycles(t *testing.T) {
+	cases := []struct {
+		src  string
+		want string
+	}{
+		{
+			src:  `(define l (list 1 2 3)) (set-cdr! (cdr (cdr l)) l) l`,
+			want: `#0=(1 2 3 . #0#)`,
+		},
+		{
+			src:  `(define l (list 1 2 3)) (set-cdr! (cdr (cdr l)) (cdr l)) l`,
+			want: `(1 . #0=(2 3 . #0#))`,
+		},
+		{
+			src:  `(define p (list 1)) (set-car! p p) p`,
+			want: `#0=(#0#)`,
+		},
+		{
+			src:  `(define a (list 1)) (define b (list a a)) (set-cdr! a a) b`,
+			want: `(#0=(1 . #0#) #0#)`,
+		},
+		{
+			src:  `(define a (list 1)) (list a a)`,
+			want: `((1) (1))`,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		v, err := NewInterpreter().Eval(c.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := Repr(v); got != c.want {
+			t.Errorf("got:  %v\nwant: %v", got, c.want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package scheme
+
+import "errors"
+
+var errInvalidSplice = errors.New("unquote-splicing must produce a proper list")
+
+// datum converts expr to the value it denotes when quoted. Literals denote
+// themselves, other tokens denote symbols, and compound expressions denote
+// lists, with "." introducing the final cdr of an improper list.
+func datum(expr expression) (value, error) {
+	switch e := expr.(type) {
+	case *tokenExpression:
+		return tokenDatum(e)
+	case *compoundExpression:
+		if e.vector {
+			return vectorDatum(e)
+		}
+
+		elems, last, err := splitDotted(e.children)
+		if err != nil {
+			return nil, err
+		}
+
+		var tail value = nullValue{}
+		if last != nil {
+			if tail, err = datum(last); err != nil {
+				return nil, err
+			}
+		}
+
+		vals := make([]value, len(elems))
+		for i, c := range elems {
+			if vals[i], err = datum(c); err != nil {
+				return nil, err
+			}
+		}
+
+		return makeListWithTail(vals, tail), nil
+	default:
+		return nil, errInvalidExpressionType
+	}
+}
+
+func tokenDatum(expr *tokenExpression) (value, error) {
+	t, err := classifyToken(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	// null is only a name for the empty list when evaluated, so quoted it is a
+	// symbol like any other.
+	if t == exprDereference || t == exprNull {
+		return intern(expr.name()), nil
+	}
+
+	// Literals evaluate to themselves, without reference to an environment.
+	return eval(expr, nil)
+}
+
+// splitDotted splits the children of a compound datum into its elements and,
+// if the datum is written as an improper list (a b . c), its final cdr.
+func splitDotted(exprs []expression) ([]expression, expression, error) {
+	var last expression
+
+	if n := len(exprs); n >= 2 && isDot(exprs[n-2]) {
+		if n == 2 {
+			return nil, nil, errInvalidCompoundExpression
+		}
+
+		last = exprs[n-1]
+		exprs = exprs[:n-2]
+	}
+
+	for _, c := range exprs {
+		if isDot(c) {
+			return nil, nil, errInvalidCompoundExpression
+		}
+	}
+
+	if last != nil && isDot(last) {
+		return nil, nil, errInvalidCompoundExpression
+	}
+
+	return exprs, last, nil
+}
+
+func isDot(expr expression) bool {
+	t, ok := expr.(*tokenExpression)
+	return ok && t.token == "."
+}
+
+// formName returns the keyword and argument of expr, if expr is a compound
+// expression of the form (keyword argument).
+func formName(expr expression) (string, expression, bool) {
+	c, ok := expr.(*compoundExpression)
+	if !ok || c.vector || len(c.children) != 2 {
+		return "", nil, false
+	}
+
+	head, ok := c.children[0].(*tokenExpression)
+	if !ok {
+		return "", nil, false
+	}
+
+	return head.name(), c.children[1], true
+}
+
+// evalQuasiquote converts expr to a datum like quote, except that unquoted
+// expressions at nesting depth 1 are evaluated within env. Nested quasiquotes
+// increase the depth and unquotes decrease it.
+func evalQuasiquote(expr expression, depth int, env *frame) (value, error) {
+	if name, arg, ok := formName(expr); ok {
+		switch name {
+		case "unquote":
+			if depth == 1 {
+				return eval(arg, env)
+			}
+			return quasiquoteForm(name, arg, depth-1, env)
+		case "quasiquote":
+			return quasiquoteForm(name, arg, depth+1, env)
+		}
+	}
+
+	c, ok := expr.(*compoundExpression)
+	if !ok {
+		return datum(expr)
+	}
+
+	// A vector template is processed as a list of its elements.
+	if c.vector {
+		for _, child := range c.children {
+			if isDot(child) {
+				return nil, errInvalidCompoundExpression
+			}
+		}
+
+		l, err := evalQuasiquote(&compoundExpression{children: c.children, span: c.span}, depth, env)
+		if err != nil {
+			return nil, err
+		}
+
+		elems, _ := AsList(l)
+		return &vectorValue{elems: elems}, nil
+	}
+
+	elems, last, err := splitDotted(c.children)
+	if err != nil {
+		return nil, err
+	}
+
+	var tail value = nullValue{}
+	if last != nil {
+		if tail, err = evalQuasiquote(last, depth, env); err != nil {
+			return nil, err
+		}
+	}
+
+	var vals []value
+	for _, child := range elems {
+		name, arg, ok := formName(child)
+		if !ok || name != "unquote-splicing" {
+			v, err := evalQuasiquote(child, depth, env)
+			if err != nil {
+				return nil, err
+			}
+
+			vals = append(vals, v)
+			continue
+		}
+
+		if depth > 1 {
+			v, err := quasiquoteForm(name, arg, depth-1, env)
+			if err != nil {
+				return nil, err
+			}
+
+			vals = append(vals, v)
+			continue
+		}
+
+		v, err := eval(arg, env)
+		if err != nil {
+			return nil, err
+		}
+
+		spliced, ok := AsList(v)
+		if !ok {
+			return nil, locate(errInvalidSplice, child)
+		}
+
+		vals = append(vals, spliced...)
+	}
+
+	return makeListWithTail(vals, tail), nil
+}
+
+// quasiquoteForm returns the list (name datum), where datum is arg processed
+// at the given depth.
+func quasiquoteForm(name string, arg expression, depth int, env *frame) (value, error) {
+	v, err := evalQuasiquote(arg, depth, env)
+	if err != nil {
+		return nil, err
+	}
+
+	return makeList([]value{intern(name), v}), nil
+}
@@ -0,0 +1,163 @@
+package scheme
+
+import (
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestQuote(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{
+			src:  `'a`,
+			want: `a`,
+		},
+		{
+			src:  `(quote a)`,
+			want: `a`,
+		},
+		{
+			src:  `'1`,
+			want: `1`,
+		},
+		{
+			src:  `'"foo"`,
+			want: `"foo"`,
+		},
+		{
+			src:  `'#t`,
+			want: `#t`,
+		},
+		{
+			src:  `'()`,
+			want: `()`,
+		},
+		{
+			src:  `(list 'null (symbol? 'null) (null? 'null) null)`,
+			want: `(null #t #f ())`,
+		},
+		{
+			src:  "`(null ,null)",
+			want: `(null ())`,
+		},
+		{
+			src:  `(list (symbol? 'a) (symbol? "a") (symbol? '(a)) (symbol? (string->symbol "b")))`,
+			want: `(#t #f #f #t)`,
+		},
+		{
+			src:  `'(a (b "c") 1 #f)`,
+			want: `(a (b "c") 1 #f)`,
+		},
+		{
+			src:  `'(a . b)`,
+			want: `(a . b)`,
+		},
+		{
+			src:  `'(a b . (c d))`,
+			want: `(a b c d)`,
+		},
+		{
+			src:  `''a`,
+			want: `(quote a)`,
+		},
+		{
+			src:  `'(if (car x) ,y)`,
+			want: `(if (car x) (unquote y))`,
+		},
+		{
+			src:  `(car '(a b))`,
+			want: `a`,
+		},
+		{
+			src:  `(eq? 'a 'a)`,
+			want: `#t`,
+		},
+		{
+			src:  `(eq? 'a 'b)`,
+			want: `#f`,
+		},
+		{
+			src:     `'(a .)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `'(. a)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:     `'(a . b c)`,
+			wantErr: errInvalidCompoundExpression,
+		},
+		{
+			src:  "`a",
+			want: `a`,
+		},
+		{
+			src:  "`(a ,(+ 1 2) c)",
+			want: `(a 3 c)`,
+		},
+		{
+			src:  "`(1 ,@(list 2 3) 4)",
+			want: `(1 2 3 4)`,
+		},
+		{
+			src:  "`(1 ,@(list) 2)",
+			want: `(1 2)`,
+		},
+		{
+			src:  "`(,@(list 1 2))",
+			want: `(1 2)`,
+		},
+		{
+			src:  "`(1 . ,(+ 1 1))",
+			want: `(1 . 2)`,
+		},
+		{
+			src:  "`((nested ,(car '(x))) \"s\")",
+			want: `((nested x) "s")`,
+		},
+		{
+			src:  "(let ((x 5)) `(x ,x))",
+			want: `(x 5)`,
+		},
+		{
+			src:  "`(1 `(2 ,(3 ,(+ 1 3))))",
+			want: `(1 (quasiquote (2 (unquote (3 4)))))`,
+		},
+		{
+			src:  "`(1 `(2 ,@(3 ,@(list 4 5))))",
+			want: `(1 (quasiquote (2 (unquote-splicing (3 4 5)))))`,
+		},
+		{
+			src:     "`(1 ,@2)",
+			wantErr: errInvalidSplice,
+		},
+		{
+			src:     `,a`,
+			wantErr: errUnquoteOutsideQuasiquote,
+		},
+		{
+			src:     "`(1 ,undefined)",
+			wantErr: errBindingNotFound,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := NewInterpreter().Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
+		}
+	}
+}
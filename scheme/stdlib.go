@@ -0,0 +1,41 @@
+package scheme
+
+import "fmt"
+
+var stdlib *frame
+
+// src defines the parts of the standard library written in Scheme, on top of
+// the primitives.
+const src = `
+(define true #t)
+(define false #f)
+(define (not a) (if a false true))
+(define (xor a b) (if a (not b) b))
+(define (list . params) params)
+(define real? number?)
+(define (inexact? a) (not (exact? a)))
+(define (finite? a) (not (or (nan? a) (infinite? a))))
+(define (square a) (* a a))
+(define exact->inexact inexact)
+(define inexact->exact exact)
+`
+
+func init() {
+	stdlib = newFrame()
+
+	for name, fn := range primitives {
+		stdlib.set(name, &builtinValue{name: name, fn: fn})
+	}
+
+	exprs, err := parseSource("stdlib", src)
+	if err != nil {
+		panic("failed to parse stdlib source: " + err.Error())
+	}
+
+	for _, expr := range exprs {
+		_, err = eval(expr, stdlib)
+		if err != nil {
+			panic(fmt.Sprintf("failed to evaluate: %v:\nerror: %v", expr, err))
+		}
+	}
+}
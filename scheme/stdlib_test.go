@@ -0,0 +1,361 @@
+package scheme
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStdlib(t *testing.T) {
+	env := stdlib.extend()
+
+	cases := []struct {
+		src  string
+		want value
+	}{
+		{
+			src:  `true`,
+			want: boolValue{true},
+		},
+		{
+			src:  `false`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(+ 1 1)`,
+			want: numberValue{2},
+		},
+		{
+			src:  `(- 1 1)`,
+			want: numberValue{0},
+		},
+		{
+			src:  `(* 2 1)`,
+			want: numberValue{2},
+		},
+		{
+			src:  `(/ 6 2)`,
+			want: numberValue{3},
+		},
+		{
+			src:  `(not true)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(not false)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(or true false)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(or false true)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(or false false)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(or true true)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(and true false)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(and false true)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(and false false)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(and true true)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(and)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(or)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(and 1 2 3)`,
+			want: numberValue{3},
+		},
+		{
+			src:  `(and 1 false undefined)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(or false 2 undefined)`,
+			want: numberValue{2},
+		},
+		{
+			src:  `(or false false false)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(and (pair? 1) (car 1))`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(and (pair? (list 1)) (car (list 1)))`,
+			want: numberValue{1},
+		},
+		{
+			src:  `(null? (list))`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(null? (list 1))`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(xor true false)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(xor false true)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(xor false false)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(xor true true)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(= 1 1)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(= 1 0)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(eq? true true)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(eq? true false)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(eq? "a" "a")`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(let ((s "a")) (eq? s s))`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(let ((s (string-append "a" "b"))) (list (eq? s s) (eq? s (string-append "a" "b"))))`,
+			want: makeList([]value{boolValue{true}, boolValue{false}}),
+		},
+		{
+			src:  `(let ((x (/ 0.0 0.0))) (list (eq? x x) (eqv? x x) (eqv? 0.0 -0.0)))`,
+			want: makeList([]value{boolValue{true}, boolValue{true}, boolValue{false}}),
+		},
+		{
+			src:  `(eqv? "a" "a")`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(eq? 100000000000000000000 100000000000000000000)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(let ((n 100000000000000000000)) (eq? n n))`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(list (eq? 1 1) (eq? #\a #\a) (eq? '() '()) (eq? "" ""))`,
+			want: makeList([]value{boolValue{true}, boolValue{true}, boolValue{true}, boolValue{true}}),
+		},
+		{
+			src:  `(eq? (list 1) (list 1))`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(let ((l (list 1))) (eq? l l))`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(eqv? 2 (+ 1 1))`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(eqv? (cons 1 2) (cons 1 2))`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(equal? (list 1 (list "a" 'b)) (list 1 (list "a" 'b)))`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(equal? (list 1 2) (list 1 3))`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(let ((p (cons 1 2))) (set-car! p 3) p)`,
+			want: &pairValue{numberValue{3}, numberValue{2}},
+		},
+		{
+			src:  `(let ((p (cons 1 2))) (set-cdr! p 3) p)`,
+			want: &pairValue{numberValue{1}, numberValue{3}},
+		},
+		{
+			src: `(let ((a (list 1 2)) (b (list 1 2)))
+			        (set-cdr! (cdr a) a)
+			        (set-cdr! (cdr b) b)
+			        (equal? a b))`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(> 1 0)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(> 0 1)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(> 1 1)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(>= 1 0)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(>= 0 1)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(>= 1 1)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(< 1 0)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(< 0 1)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(< 1 1)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(<= 1 0)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(<= 0 1)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(<= 1 1)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(< 1 2 3)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(< 1 3 2)`,
+			want: boolValue{false},
+		},
+		{
+			src:  `(<= 1 1 2)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(>= 3 3 1)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(= 2 2 2)`,
+			want: boolValue{true},
+		},
+		{
+			src:  `(+ 1 2 3 4)`,
+			want: numberValue{10},
+		},
+		{
+			src:  `(- 10 1 2)`,
+			want: numberValue{7},
+		},
+		{
+			src:  `(- 5)`,
+			want: numberValue{-5},
+		},
+		{
+			src:  `(*)`,
+			want: numberValue{1},
+		},
+		{
+			src:  `(/ 60 2 3)`,
+			want: numberValue{10},
+		},
+		{
+			src:  `(let ((add +)) (add 1 2 3))`,
+			want: numberValue{6},
+		},
+		{
+			src:  `(cons 1 2)`,
+			want: &pairValue{numberValue{1}, numberValue{2}},
+		},
+		{
+			src:  `(car (cons 1 2))`,
+			want: numberValue{1},
+		},
+		{
+			src:  `(cdr (cons 1 2))`,
+			want: numberValue{2},
+		},
+		{
+			src:  `(list)`,
+			want: nullValue{},
+		},
+		{
+			src:  `(list 1)`,
+			want: makeList([]value{numberValue{1}}),
+		},
+		{
+			src:  `(list (+ 1 1) (+ 2 2))`,
+			want: makeList([]value{numberValue{2}, numberValue{4}}),
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		exprs, err := parseSource("", c.src)
+		if err != nil {
+			t.Fatal("parse error:", err)
+		}
+
+		if len(exprs) != 1 {
+			t.Fatal("should be exactly one top-level expression: ", exprs)
+		}
+
+		got, err := eval(exprs[0], env)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("value:\ngot:  %v\nwant: %v", got, c.want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package scheme
+
+import (
+	"errors"
+	"fmt"
+
+	"scgeme/errs"
+)
+
+var (
+	errUnclosedString  = errors.New("unclosed string")
+	errMultilineString = errors.New("string literal spans multiple lines")
+	errUnclosedComment = errors.New("unclosed block comment")
+)
+
+// position identifies a location in source text. Lines and columns are
+// counted in runes from 1.
+type position struct {
+	file string
+	line int
+	col  int
+}
+
+func (p position) String() string {
+	if p.file == "" {
+		return fmt.Sprintf("%d:%d", p.line, p.col)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.file, p.line, p.col)
+}
+
+// span is the extent of a piece of source text, from the position of its
+// first rune up to, but not including, end.
+type span struct {
+	start position
+	end   position
+}
+
+func (s span) String() string {
+	return s.start.String()
+}
+
+// token is a lexeme along with its extent in the source.
+type token struct {
+	text string
+	span span
+}
+
+var escapes = map[rune]string{
+	'a': "\a",
+	'b': "\b",
+	'f': "\f",
+	'n': "\n",
+	'r': "\r",
+	't': "\t",
+	'v': "\v",
+}
+
+// datumCommentToken is emitted for "#;", which comments out the following
+// datum. It is removed by parse, since the extent of a datum is only known
+// once the tokens are grouped.
+const datumCommentToken = "#;"
+
+// prefixForms maps the reader shorthands that prefix a datum to the forms
+// they abbreviate, so that 'x reads as (quote x).
+var prefixForms = map[string]string{
+	"'":  "quote",
+	"`":  "quasiquote",
+	",":  "unquote",
+	",@": "unquote-splicing",
+}
+
+// tokenize splits src into tokens, recording positions against the given file
+// name.
+func tokenize(file, src string) ([]token, error) {
+	var (
+		res     []token
+		current string
+		start   position // position of the first rune of current
+
+		isString     bool
+		isEscape     bool
+		isComment    bool
+		blockComment []position // positions of open #| ... |# comments
+
+		pos = position{file: file, line: 1, col: 1}
+
+		// finishCurrent emits the pending token, which ends just before end.
+		finishCurrent = func(end position) {
+			if len(current) > 0 {
+				res = append(res, token{text: current, span: span{start, end}})
+				current = ""
+			}
+		}
+
+		// single returns the span of the single rune at pos.
+		single = func() span {
+			end := pos
+			end.col++
+			return span{pos, end}
+		}
+
+		addRune = func(r rune) {
+			if current == "" {
+				start = pos
+			}
+			current += string(r)
+		}
+	)
+
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); i, pos.col = i+1, pos.col+1 {
+		r := runes[i]
+
+		var next rune
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		if isComment {
+			if r == '\n' || r == '\r' {
+				isComment = false
+			}
+		} else if len(blockComment) > 0 {
+			if r == '|' && next == '#' {
+				blockComment = blockComment[:len(blockComment)-1]
+				i, pos.col = i+1, pos.col+1
+			} else if r == '#' && next == '|' {
+				blockComment = append(blockComment, pos)
+				i, pos.col = i+1, pos.col+1
+			}
+		} else if isString && isEscape {
+			if s, ok := escapes[r]; ok {
+				current += s
+			} else {
+				current += string(r)
+			}
+			isEscape = false
+		} else if isString {
+			switch r {
+			case '"':
+				current += string(r)
+				finishCurrent(single().end)
+				isString = false
+				isEscape = false
+			case '\\':
+				isEscape = true
+			case '\n', '\r':
+				return nil, errs.Wrap(errMultilineString, start.String())
+			default:
+				current += string(r)
+			}
+		} else {
+			switch {
+			case r == '(' || r == ')':
+				finishCurrent(pos)
+				res = append(res, token{text: string(r), span: single()})
+			case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+				finishCurrent(pos)
+			case r == '"':
+				finishCurrent(pos)
+				addRune(r)
+				isString = true
+			case r == ';':
+				finishCurrent(pos)
+				isComment = true
+			case r == '#' && next == '|' && current == "":
+				blockComment = append(blockComment, pos)
+				i, pos.col = i+1, pos.col+1
+			case r == '\'' || r == '`':
+				finishCurrent(pos)
+				res = append(res, token{text: string(r), span: single()})
+			case r == ',' && next == '@':
+				finishCurrent(pos)
+				sp := single()
+				sp.end.col++
+				res = append(res, token{text: ",@", span: sp})
+				i, pos.col = i+1, pos.col+1
+			case r == ',':
+				finishCurrent(pos)
+				res = append(res, token{text: string(r), span: single()})
+			case r == '#' && next == '\\' && current == "" && i+2 < len(runes) && runes[i+2] != '\n' && runes[i+2] != '\r':
+				// The character after #\ is part of the literal even if it
+				// is a delimiter, as in #\( or #\space.
+				addRune(r)
+				current += string(next) + string(runes[i+2])
+				i, pos.col = i+2, pos.col+2
+			case r == '#' && next == '(' && current == "":
+				sp := single()
+				sp.end.col++
+				res = append(res, token{text: vectorToken, span: sp})
+				i, pos.col = i+1, pos.col+1
+			case r == '#' && next == 'u' && current == "" && i+3 < len(runes) && runes[i+2] == '8' && runes[i+3] == '(':
+				sp := single()
+				sp.end.col += 3
+				res = append(res, token{text: bytevectorToken, span: sp})
+				i, pos.col = i+3, pos.col+3
+			case r == '#' && next == ';' && current == "":
+				sp := single()
+				sp.end.col++
+				res = append(res, token{text: datumCommentToken, span: sp})
+				i, pos.col = i+1, pos.col+1
+			default:
+				addRune(r)
+			}
+		}
+
+		if r == '\n' {
+			pos.line++
+			pos.col = 0
+		}
+	}
+
+	if isString {
+		return nil, errs.Wrap(errUnclosedString, start.String())
+	}
+
+	if len(blockComment) > 0 {
+		return nil, errs.Wrap(errUnclosedComment, blockComment[0].String())
+	}
+
+	finishCurrent(pos)
+
+	return res, nil
+}
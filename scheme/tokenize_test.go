@@ -0,0 +1,223 @@
+package scheme
+
+import (
+	"reflect"
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		src  string
+		want []string
+	}{
+		{
+			src:  `foo`,
+			want: []string{"foo"},
+		},
+		{
+			src:  `foo bar`,
+			want: []string{"foo", "bar"},
+		},
+		{
+			src:  `(foo)`,
+			want: []string{"(", "foo", ")"},
+		},
+		{
+			src:  `(foo bar)`,
+			want: []string{"(", "foo", "bar", ")"},
+		},
+		{
+			src: `
+				foo
+				bar
+				baz
+			`,
+			want: []string{"foo", "bar", "baz"},
+		},
+		{
+			src: `
+				(lambda (foo)
+				  (+ foo foo))
+			`,
+			want: []string{"(", "lambda", "(", "foo", ")", "(", "+", "foo", "foo", ")", ")"},
+		},
+		{
+			src:  `1 "foo"`,
+			want: []string{"1", `"foo"`},
+		},
+		{
+			src:  `1"foo"`,
+			want: []string{"1", `"foo"`},
+		},
+		{
+			src:  `"foo bar"`,
+			want: []string{`"foo bar"`},
+		},
+		{
+			src:  `"foo \"bar \\"`,
+			want: []string{`"foo "bar \"`},
+		},
+		{
+			src:  `"foo\nbar"`,
+			want: []string{"\"foo\nbar\""},
+		},
+		{
+			src:  `""`,
+			want: []string{"\"\""},
+		},
+		{
+			src:  "foo ; bar baz\nqux",
+			want: []string{"foo", "qux"},
+		},
+		{
+			src:  "(foo;bar)\n)",
+			want: []string{"(", "foo", ")"},
+		},
+		{
+			src:  `"foo ; bar"`,
+			want: []string{`"foo ; bar"`},
+		},
+		{
+			src:  `; only a comment`,
+			want: nil,
+		},
+		{
+			src:  `foo #| bar |# baz`,
+			want: []string{"foo", "baz"},
+		},
+		{
+			src:  `foo #| bar #| baz |# qux |# quux`,
+			want: []string{"foo", "quux"},
+		},
+		{
+			src:  "#| multi\nline ; |# foo",
+			want: []string{"foo"},
+		},
+		{
+			src:  `foo#|bar|#`,
+			want: []string{"foo#|bar|#"},
+		},
+		{
+			src:  `"#|" foo`,
+			want: []string{`"#|"`, "foo"},
+		},
+		{
+			src:  `#;foo bar`,
+			want: []string{"#;", "foo", "bar"},
+		},
+		{
+			src:  "'foo `(a ,b ,@c)",
+			want: []string{"'", "foo", "`", "(", "a", ",", "b", ",@", "c", ")"},
+		},
+		{
+			src:  "foo'bar",
+			want: []string{"foo", "'", "bar"},
+		},
+		{
+			src:  `"'a ,b"`,
+			want: []string{`"'a ,b"`},
+		},
+		{
+			src:  `(a #; (b c) d)`,
+			want: []string{"(", "a", "#;", "(", "b", "c", ")", "d", ")"},
+		},
+		{
+			src:  `(#\a #\space #\x41)`,
+			want: []string{"(", `#\a`, `#\space`, `#\x41`, ")"},
+		},
+		{
+			src:  `(#\( #\) #\; #\")`,
+			want: []string{"(", `#\(`, `#\)`, `#\;`, `#\"`, ")"},
+		},
+		{
+			src:  `#(1 #(2)) #\#(`,
+			want: []string{"#(", "1", "#(", "2", ")", ")", `#\#`, "("},
+		},
+		{
+			src:  `#u8(1 2) #u8 u8(`,
+			want: []string{"#u8(", "1", "2", ")", "#u8", "u8", "("},
+		},
+		{
+			src:  `#\  #\'`,
+			want: []string{`#\ `, `#\'`},
+		},
+	}
+
+	for _, c := range cases {
+		toks, err := tokenize("", c.src)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.src, err)
+			continue
+		}
+
+		var got []string
+		for _, tok := range toks {
+			got = append(got, tok.text)
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s:\ngot:  %v\nwant: %v", c.src, got, c.want)
+		}
+	}
+}
+
+func TestTokenizePositions(t *testing.T) {
+	src := "(foo \"bar\"\n  #;baz) #| a\nb |# qux ; quux\n\t\"λ\"λ"
+
+	want := []token{
+		{text: "(", span: span{position{"f", 1, 1}, position{"f", 1, 2}}},
+		{text: "foo", span: span{position{"f", 1, 2}, position{"f", 1, 5}}},
+		{text: `"bar"`, span: span{position{"f", 1, 6}, position{"f", 1, 11}}},
+		{text: "#;", span: span{position{"f", 2, 3}, position{"f", 2, 5}}},
+		{text: "baz", span: span{position{"f", 2, 5}, position{"f", 2, 8}}},
+		{text: ")", span: span{position{"f", 2, 8}, position{"f", 2, 9}}},
+		{text: "qux", span: span{position{"f", 3, 6}, position{"f", 3, 9}}},
+		{text: `"λ"`, span: span{position{"f", 4, 2}, position{"f", 4, 5}}},
+		{text: "λ", span: span{position{"f", 4, 5}, position{"f", 4, 6}}},
+	}
+
+	got, err := tokenize("f", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	cases := []struct {
+		src     string
+		wantErr error
+		wantMsg string
+	}{
+		{
+			src:     `(foo "bar`,
+			wantErr: errUnclosedString,
+			wantMsg: "f:1:6: unclosed string",
+		},
+		{
+			src:     "(foo\n \"bar\nbaz\")",
+			wantErr: errMultilineString,
+			wantMsg: "f:2:2: string literal spans multiple lines",
+		},
+		{
+			src:     "foo #| bar #| baz |#\n",
+			wantErr: errUnclosedComment,
+			wantMsg: "f:1:5: unclosed block comment",
+		},
+	}
+
+	for _, c := range cases {
+		_, err := tokenize("f", c.src)
+
+		if errs.Root(err) != c.wantErr {
+			t.Errorf("%q:\ngot error:  %v\nwant error: %v", c.src, err, c.wantErr)
+		} else if err.Error() != c.wantMsg {
+			t.Errorf("%q:\ngot message:  %v\nwant message: %v", c.src, err, c.wantMsg)
+		}
+	}
+}
@@ -0,0 +1,545 @@
+package scheme
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+)
+
+var errIncomparableValueTypes = errors.New("cannot compare values of different types")
+
+type value interface {
+	valueType()
+	equals(v value) (bool, error)
+}
+
+type orderable interface {
+	greaterThan(v value) (bool, error)
+}
+
+type nullValue struct {
+}
+
+func (_ nullValue) valueType() {
+	// does nothing
+}
+
+func (_ nullValue) equals(other value) (bool, error) {
+	_, ok := other.(nullValue)
+	return ok, nil
+}
+
+type numberValue struct {
+	underlying int
+}
+
+func (_ numberValue) valueType() {
+	// does nothing
+}
+
+func (v numberValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case nullValue:
+		return false, nil
+	case numberValue:
+		return v.underlying == other.underlying, nil
+	case *numberValue:
+		return v.underlying == other.underlying, nil
+	default:
+		return false, nil
+	}
+}
+
+func (v numberValue) greaterThan(other value) (bool, error) {
+	switch other := other.(type) {
+	case numberValue:
+		return v.underlying > other.underlying, nil
+	case *numberValue:
+		return v.underlying > other.underlying, nil
+	default:
+		return numberGreaterThan(v, other)
+	}
+}
+
+type boolValue struct {
+	underlying bool
+}
+
+func (_ boolValue) valueType() {
+	// does nothing
+}
+
+func (v boolValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case boolValue:
+		return v.underlying == other.underlying, nil
+	case *boolValue:
+		return v.underlying == other.underlying, nil
+	default:
+		return false, nil
+	}
+}
+
+// isTrue reports whether v counts as true in a conditional. Every value other
+// than #f does.
+func isTrue(v value) bool {
+	b, ok := v.(boolValue)
+	return !ok || b.underlying
+}
+
+// stringValue is an immutable string. Copies of a string share its identity,
+// which strings with the same characters do not, except that all empty
+// strings are the same.
+type stringValue struct {
+	underlying string
+	id         *stringIdentity
+}
+
+// stringIdentity tells strings apart for eq?. It is not empty, so that each
+// one allocated has its own address.
+type stringIdentity struct {
+	_ byte
+}
+
+// newString returns a string with the characters of s and a new identity.
+func newString(s string) stringValue {
+	if s == "" {
+		return stringValue{}
+	}
+	return stringValue{underlying: s, id: new(stringIdentity)}
+}
+
+func (_ stringValue) valueType() {
+	// does nothing
+}
+
+func (v stringValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case stringValue:
+		return v.underlying == other.underlying, nil
+	case *stringValue:
+		return v.underlying == other.underlying, nil
+	default:
+		return false, nil
+	}
+}
+
+// greaterThan orders strings lexicographically by code point.
+func (v stringValue) greaterThan(other value) (bool, error) {
+	switch other := other.(type) {
+	case stringValue:
+		return v.underlying > other.underlying, nil
+	case *stringValue:
+		return v.underlying > other.underlying, nil
+	default:
+		return false, errIncomparableValueTypes
+	}
+}
+
+// symbolValue is an interned identifier. There is exactly one symbolValue for
+// each name, so symbols can be compared by pointer.
+type symbolValue struct {
+	name string
+}
+
+var (
+	symbolsMu sync.Mutex
+	symbols   = make(map[string]*symbolValue)
+)
+
+// intern returns the symbol with the given name.
+func intern(name string) *symbolValue {
+	symbolsMu.Lock()
+	defer symbolsMu.Unlock()
+
+	if s, ok := symbols[name]; ok {
+		return s
+	}
+
+	s := &symbolValue{name: name}
+	symbols[name] = s
+	return s
+}
+
+func (_ *symbolValue) valueType() {
+	// does nothing
+}
+
+func (v *symbolValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case *symbolValue:
+		return v == other, nil
+	default:
+		return false, nil
+	}
+}
+
+// pairValue is a mutable pair. Pairs are always handled by pointer, so that
+// a pair has an identity that mutation through set-car! and set-cdr! preserves.
+type pairValue struct {
+	car value
+	cdr value
+}
+
+func (_ *pairValue) valueType() {
+	// does nothing
+}
+
+func (v *pairValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case *pairValue:
+		return v == other, nil
+	default:
+		return false, nil
+	}
+}
+
+// eqvValues reports whether a and b are the same in the sense of eqv?.
+func eqvValues(a, b value) bool {
+	eq, _ := a.equals(b)
+	return eq
+}
+
+// identical reports whether a and b are the same object, in the sense of eq?.
+// Fixnums, characters, booleans and the empty list have no identity apart
+// from their value, and symbols are interned. Strings are only identical if
+// they are copies of the same string.
+func identical(a, b value) bool {
+	switch a := a.(type) {
+	case stringValue:
+		b, ok := b.(stringValue)
+		return ok && a.id == b.id
+	case floatValue:
+		// Inexact numbers are boxed in other Schemes, so need not be eq?
+		// even when they are eqv?. They are compared as eqv? does here, by
+		// their bits, so that NaN is eq? to itself.
+		eq, _ := a.equals(b)
+		return eq
+	default:
+		// Other values are either pointers or immediate values, and bignums
+		// and rationals hold pointers to their digits.
+		return a == b
+	}
+}
+
+// equalValues reports whether a and b are equal in the sense of equal?: pairs
+// are equal if their cars and cdrs are, vectors if their elements are,
+// bytevectors if their bytes are, and other values if they are eqv?.
+func equalValues(a, b value) bool {
+	return deepEqual(a, b, make(map[[2]value]bool))
+}
+
+// deepEqual compares a and b like equalValues. Pairs and vectors already being
+// compared are assumed to be equal, so that the comparison of cyclic
+// structures ends.
+func deepEqual(a, b value, seen map[[2]value]bool) bool {
+	for {
+		if va, ok := a.(*vectorValue); ok {
+			return deepEqualVectors(va, b, seen)
+		}
+
+		if ba, ok := a.(*bytevectorValue); ok {
+			bb, ok := b.(*bytevectorValue)
+			return ok && bytes.Equal(ba.bytes, bb.bytes)
+		}
+
+		pa, ok := a.(*pairValue)
+		pb, ok2 := b.(*pairValue)
+		if !ok || !ok2 {
+			eq, _ := a.equals(b)
+			return eq
+		}
+
+		k := [2]value{pa, pb}
+		if pa == pb || seen[k] {
+			return true
+		}
+		seen[k] = true
+
+		if !deepEqual(pa.car, pb.car, seen) {
+			return false
+		}
+
+		// Compare the rest of a list iteratively, so long lists do not
+		// recurse deeply.
+		a, b = pa.cdr, pb.cdr
+	}
+}
+
+func deepEqualVectors(va *vectorValue, b value, seen map[[2]value]bool) bool {
+	vb, ok := b.(*vectorValue)
+	if !ok || len(va.elems) != len(vb.elems) {
+		return false
+	}
+
+	k := [2]value{va, vb}
+	if va == vb || seen[k] {
+		return true
+	}
+	seen[k] = true
+
+	for i := range va.elems {
+		if !deepEqual(va.elems[i], vb.elems[i], seen) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func makeList(vals []value) value {
+	return makeListWithTail(vals, nullValue{})
+}
+
+// makeListWithTail returns a list of vals whose final cdr is tail, which is
+// improper unless tail is itself a list.
+func makeListWithTail(vals []value, tail value) value {
+	res := tail
+	for i := len(vals) - 1; i >= 0; i-- {
+		res = &pairValue{car: vals[i], cdr: res}
+	}
+	return res
+}
+
+type procValue struct {
+	name    string // name of the first binding, if defined with define
+	formals []string
+	rest    string
+	body    []expression
+	env     *frame
+}
+
+func (_ *procValue) valueType() {
+	// does nothing
+}
+
+func (v *procValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case *procValue:
+		return v == other, nil
+	default:
+		return false, nil
+	}
+}
+
+// bind returns a new frame extending the procedure's environment, with its
+// formal parameters bound to args.
+func (v *procValue) bind(args []value) (*frame, error) {
+	if len(args) < len(v.formals) {
+		return nil, errWrongNumberOfArguments
+	}
+	if len(args) > len(v.formals) && v.rest == "" {
+		return nil, errWrongNumberOfArguments
+	}
+
+	res := v.env.extend()
+
+	for i, param := range v.formals {
+		res.set(param, args[i])
+	}
+
+	if v.rest != "" {
+		res.set(v.rest, makeList(args[len(v.formals):]))
+	}
+
+	return res, nil
+}
+
+// builtinValue is a procedure implemented in Go, which receives its arguments
+// already evaluated.
+type builtinValue struct {
+	name string
+	fn   func(args []value) (value, error)
+}
+
+func (_ *builtinValue) valueType() {
+	// does nothing
+}
+
+func (v *builtinValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case *builtinValue:
+		return v == other, nil
+	default:
+		return false, nil
+	}
+}
+
+// typeName returns a short, user-facing name for the type of v, for use in
+// error messages.
+func typeName(v value) string {
+	switch v.(type) {
+	case nullValue:
+		return "null"
+	case numberValue, *numberValue, bigValue, ratValue, floatValue:
+		return "number"
+	case boolValue, *boolValue:
+		return "boolean"
+	case stringValue, *stringValue:
+		return "string"
+	case charValue:
+		return "char"
+	case *vectorValue:
+		return "vector"
+	case *bytevectorValue:
+		return "bytevector"
+	case *hashTableValue:
+		return "hash-table"
+	case *symbolValue:
+		return "symbol"
+	case *pairValue:
+		return "pair"
+	case *procValue, *builtinValue:
+		return "procedure"
+	case *macroValue, *procMacroValue:
+		return "syntax"
+	default:
+		return "unknown"
+	}
+}
+
+// Value is a Scheme value, as produced by Interpreter.Eval or bound with
+// Interpreter.Define. Values are created with the constructors below and
+// inspected with the matching As functions.
+type Value = value
+
+// Null returns the empty list.
+func Null() Value {
+	return nullValue{}
+}
+
+// Number returns a number value.
+func Number(n int) Value {
+	return numberValue{n}
+}
+
+// Float returns an inexact number value.
+func Float(f float64) Value {
+	return floatValue{f}
+}
+
+// Bool returns a boolean value.
+func Bool(b bool) Value {
+	return boolValue{b}
+}
+
+// String returns a string value.
+func String(s string) Value {
+	return newString(s)
+}
+
+// Char returns a character value.
+func Char(r rune) Value {
+	return charValue{r}
+}
+
+// Symbol returns the symbol with the given name.
+func Symbol(name string) Value {
+	return intern(name)
+}
+
+// Cons returns a pair of car and cdr.
+func Cons(car, cdr Value) Value {
+	return &pairValue{car: car, cdr: cdr}
+}
+
+// List returns a proper list of vals.
+func List(vals ...Value) Value {
+	return makeList(vals)
+}
+
+// IsNull reports whether v is the empty list.
+func IsNull(v Value) bool {
+	_, ok := v.(nullValue)
+	return ok
+}
+
+// IsProc reports whether v can be applied as a procedure.
+func IsProc(v Value) bool {
+	switch v.(type) {
+	case *procValue, *builtinValue:
+		return true
+	default:
+		return false
+	}
+}
+
+// AsNumber returns the integer held by v, if v is an exact integer that fits
+// in an int.
+func AsNumber(v Value) (int, bool) {
+	n, ok := v.(numberValue)
+	return n.underlying, ok
+}
+
+// AsFloat returns v as a float64, if v is a number of any kind.
+func AsFloat(v Value) (float64, bool) {
+	if !isNumber(v) {
+		return 0, false
+	}
+	return toFloat(v), true
+}
+
+// AsBool returns the boolean held by v, if v is a boolean.
+func AsBool(v Value) (bool, bool) {
+	b, ok := v.(boolValue)
+	return b.underlying, ok
+}
+
+// AsString returns the string held by v, if v is a string.
+func AsString(v Value) (string, bool) {
+	s, ok := v.(stringValue)
+	return s.underlying, ok
+}
+
+// AsChar returns the rune held by v, if v is a character.
+func AsChar(v Value) (rune, bool) {
+	c, ok := v.(charValue)
+	return c.underlying, ok
+}
+
+// AsSymbol returns the name of v, if v is a symbol.
+func AsSymbol(v Value) (string, bool) {
+	s, ok := v.(*symbolValue)
+	if !ok {
+		return "", false
+	}
+	return s.name, true
+}
+
+// AsPair returns the car and cdr of v, if v is a pair.
+func AsPair(v Value) (car, cdr Value, ok bool) {
+	p, ok := v.(*pairValue)
+	if !ok {
+		return nil, nil, false
+	}
+	return p.car, p.cdr, true
+}
+
+// AsList returns the elements of v, if v is a proper list. A circular list is
+// not a proper list.
+func AsList(v Value) ([]Value, bool) {
+	var res []Value
+
+	// slow follows the list at half speed, and is caught up with by v only if
+	// the list is circular.
+	slow := v
+
+	for {
+		switch l := v.(type) {
+		case nullValue:
+			return res, true
+		case *pairValue:
+			res = append(res, l.car)
+			v = l.cdr
+		default:
+			return nil, false
+		}
+
+		if len(res)%2 == 0 {
+			slow = slow.(*pairValue).cdr
+			if slow == v {
+				return nil, false
+			}
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package scheme
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValueEqual(t *testing.T) {
+	var (
+		testProc procValue
+		testPair pairValue
+	)
+
+	cases := []struct {
+		a    value
+		b    value
+		want bool
+	}{
+		{
+			a:    nullValue{},
+			b:    nullValue{},
+			want: true,
+		},
+		{
+			a:    numberValue{underlying: 1},
+			b:    numberValue{underlying: 1},
+			want: true,
+		},
+		{
+			a:    numberValue{underlying: 1},
+			b:    &numberValue{underlying: 1},
+			want: true,
+		},
+		{
+			a:    numberValue{underlying: 1},
+			b:    numberValue{underlying: 2},
+			want: false,
+		},
+		{
+			a:    numberValue{underlying: 1},
+			b:    &numberValue{underlying: 2},
+			want: false,
+		},
+		{
+			a:    boolValue{underlying: true},
+			b:    boolValue{underlying: true},
+			want: true,
+		},
+		{
+			a:    boolValue{underlying: true},
+			b:    &boolValue{underlying: true},
+			want: true,
+		},
+		{
+			a:    boolValue{underlying: true},
+			b:    boolValue{underlying: false},
+			want: false,
+		},
+		{
+			a:    boolValue{underlying: true},
+			b:    &boolValue{underlying: false},
+			want: false,
+		},
+		{
+			a:    stringValue{underlying: "foo"},
+			b:    &stringValue{underlying: "foo"},
+			want: true,
+		},
+		{
+			a:    stringValue{underlying: "foo"},
+			b:    &stringValue{underlying: "bar"},
+			want: false,
+		},
+		{
+			a:    &testPair,
+			b:    &testPair,
+			want: true,
+		},
+		{
+			a:    &pairValue{car: numberValue{1}, cdr: numberValue{2}},
+			b:    &pairValue{car: numberValue{1}, cdr: numberValue{2}},
+			want: false,
+		},
+		{
+			a:    intern("foo"),
+			b:    intern("foo"),
+			want: true,
+		},
+		{
+			a:    intern("foo"),
+			b:    &symbolValue{name: "foo"},
+			want: false,
+		},
+		{
+			a:    intern("foo"),
+			b:    newString("foo"),
+			want: false,
+		},
+		{
+			a:    &testProc,
+			b:    &testProc,
+			want: true,
+		},
+		{
+			a:    new(procValue),
+			b:    new(procValue),
+			want: false,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v.equals(%v)", i, c.a, c.b)
+
+		got, err := c.a.equals(c.b)
+		if err != nil {
+			t.Errorf("error:\ngot:  %v\nwant: %v", err, nil)
+			continue
+		}
+
+		if got != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", got, c.want)
+		}
+	}
+}
+
+func TestEqualValues(t *testing.T) {
+	cyclic := func(vals ...value) value {
+		l := makeList(vals)
+		last := l.(*pairValue)
+		for next, ok := last.cdr.(*pairValue); ok; next, ok = last.cdr.(*pairValue) {
+			last = next
+		}
+		last.cdr = l
+		return l
+	}
+
+	cases := []struct {
+		a    value
+		b    value
+		want bool
+	}{
+		{
+			a:    numberValue{1},
+			b:    numberValue{1},
+			want: true,
+		},
+		{
+			a:    makeList([]value{numberValue{1}, newString("a")}),
+			b:    makeList([]value{numberValue{1}, newString("a")}),
+			want: true,
+		},
+		{
+			a:    makeList([]value{numberValue{1}, makeList([]value{intern("a")})}),
+			b:    makeList([]value{numberValue{1}, makeList([]value{intern("a")})}),
+			want: true,
+		},
+		{
+			a:    makeList([]value{numberValue{1}, numberValue{2}}),
+			b:    makeList([]value{numberValue{1}}),
+			want: false,
+		},
+		{
+			a:    makeListWithTail([]value{numberValue{1}}, numberValue{2}),
+			b:    makeListWithTail([]value{numberValue{1}}, numberValue{3}),
+			want: false,
+		},
+		{
+			a:    cyclic(numberValue{1}, numberValue{2}),
+			b:    cyclic(numberValue{1}, numberValue{2}),
+			want: true,
+		},
+		{
+			a:    cyclic(numberValue{1}, numberValue{2}),
+			b:    cyclic(numberValue{1}, numberValue{3}),
+			want: false,
+		},
+		{
+			a:    cyclic(numberValue{1}),
+			b:    cyclic(numberValue{1}, numberValue{1}),
+			want: true,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d", i)
+
+		if got := equalValues(c.a, c.b); got != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", got, c.want)
+		}
+	}
+}
+
+func TestValueIncomparable(t *testing.T) {
+	vals := []value{
+		nullValue{},
+		numberValue{},
+		boolValue{},
+		stringValue{},
+		&pairValue{},
+		intern("foo"),
+		new(procValue),
+	}
+
+	for i, v1 := range vals {
+		if i == len(vals)-1 {
+			break
+		}
+
+		for _, v2 := range vals[i+1:] {
+			t.Logf("Case: %v and %v", v1, v2)
+
+			var (
+				eq  bool
+				err error
+			)
+
+			eq, err = v1.equals(v2)
+			if err != nil {
+				t.Error("error:", err)
+			}
+			if eq {
+				t.Error("should not be equal")
+			}
+
+			eq, err = v2.equals(v1)
+			if err != nil {
+				t.Error("error:", err)
+			}
+			if eq {
+				t.Error("should not be equal")
+			}
+		}
+	}
+}
+
+func TestValueAccessors(t *testing.T) {
+	if n, ok := AsNumber(Number(3)); !ok || n != 3 {
+		t.Errorf("AsNumber(Number(3)): got %v, %v", n, ok)
+	}
+	if _, ok := AsNumber(String("3")); ok {
+		t.Error("AsNumber(String(\"3\")) should fail")
+	}
+
+	if b, ok := AsBool(Bool(true)); !ok || !b {
+		t.Errorf("AsBool(Bool(true)): got %v, %v", b, ok)
+	}
+	if _, ok := AsBool(Null()); ok {
+		t.Error("AsBool(Null()) should fail")
+	}
+
+	if s, ok := AsString(String("foo")); !ok || s != "foo" {
+		t.Errorf("AsString(String(\"foo\")): got %v, %v", s, ok)
+	}
+	if _, ok := AsString(Number(1)); ok {
+		t.Error("AsString(Number(1)) should fail")
+	}
+
+	if car, cdr, ok := AsPair(Cons(Number(1), Number(2))); !ok || car != Number(1) || cdr != Number(2) {
+		t.Errorf("AsPair(Cons(1, 2)): got %v, %v, %v", car, cdr, ok)
+	}
+	if _, _, ok := AsPair(Null()); ok {
+		t.Error("AsPair(Null()) should fail")
+	}
+
+	if name, ok := AsSymbol(Symbol("foo")); !ok || name != "foo" {
+		t.Errorf("AsSymbol(Symbol(\"foo\")): got %v, %v", name, ok)
+	}
+	if Symbol("foo") != Symbol("foo") {
+		t.Error("symbols should be interned")
+	}
+	if _, ok := AsSymbol(String("foo")); ok {
+		t.Error("AsSymbol(String(\"foo\")) should fail")
+	}
+
+	if !IsNull(Null()) || IsNull(Number(0)) {
+		t.Error("IsNull mismatch")
+	}
+
+	if !IsProc(new(procValue)) || IsProc(Number(0)) {
+		t.Error("IsProc mismatch")
+	}
+
+	vals := []Value{Number(1), String("a"), Bool(false)}
+	if got, ok := AsList(List(vals...)); !ok || !reflect.DeepEqual(got, vals) {
+		t.Errorf("AsList(List(...)): got %v, %v", got, ok)
+	}
+	if got, ok := AsList(Null()); !ok || len(got) != 0 {
+		t.Errorf("AsList(Null()): got %v, %v", got, ok)
+	}
+	if _, ok := AsList(Cons(Number(1), Number(2))); ok {
+		t.Error("AsList of improper list should fail")
+	}
+
+	for n := 1; n <= 3; n++ {
+		l := makeList(vals[:n])
+		last := l.(*pairValue)
+		for next, ok := last.cdr.(*pairValue); ok; next, ok = last.cdr.(*pairValue) {
+			last = next
+		}
+		last.cdr = l
+
+		if _, ok := AsList(l); ok {
+			t.Errorf("AsList of circular list of length %d should fail", n)
+		}
+	}
+}
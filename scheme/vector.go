@@ -0,0 +1,43 @@
+package scheme
+
+// vectorValue is a fixed-length, mutable sequence of values with constant-time
+// indexing. Like pairs, vectors are compared by identity.
+type vectorValue struct {
+	elems []value
+}
+
+func (_ *vectorValue) valueType() {
+	// does nothing
+}
+
+func (v *vectorValue) equals(other value) (bool, error) {
+	switch other := other.(type) {
+	case *vectorValue:
+		return v == other, nil
+	default:
+		return false, nil
+	}
+}
+
+// vectorToken opens a vector literal, #(elements...), which is parsed as a
+// compound expression marked as a vector.
+const vectorToken = "#("
+
+// vectorDatum returns the vector denoted by the literal expr. Unlike a list,
+// a vector cannot be written with a dotted tail.
+func vectorDatum(expr *compoundExpression) (value, error) {
+	elems := make([]value, len(expr.children))
+	for i, c := range expr.children {
+		if isDot(c) {
+			return nil, errInvalidCompoundExpression
+		}
+
+		v, err := datum(c)
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = v
+	}
+
+	return &vectorValue{elems: elems}, nil
+}
@@ -0,0 +1,75 @@
+package scheme
+
+import (
+	"testing"
+
+	"scgeme/errs"
+)
+
+func TestVectors(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    string
+		wantErr error
+	}{
+		{src: `#(1 2 3)`, want: `#(1 2 3)`},
+		{src: `#()`, want: `#()`},
+		{src: `#(a (b c) "d" #\e #(1))`, want: `#(a (b c) "d" #\e #(1))`},
+		{src: `'(1 #(2 3))`, want: `(1 #(2 3))`},
+		{src: "(let ((x 2)) `#(1 ,x ,@(list 3 4)))", want: `#(1 2 3 4)`},
+		{src: "`(1 #(a ,(+ 1 1)))", want: `(1 #(a 2))`},
+		{src: `(list (vector? #(1)) (vector? '(1)) (vector? "a"))`, want: `(#t #f #f)`},
+		{src: `(list (make-vector 2 'x) (make-vector 0) (vector) (vector 1 "a"))`, want: `(#(x x) #() #() #(1 "a"))`},
+		{src: `(list (vector-length #()) (vector-length #(1 2 3)))`, want: `(0 3)`},
+		{src: `(list (vector-ref #(a b c) 0) (vector-ref #(a b c) 2))`, want: `(a c)`},
+		{src: `(let ((v (make-vector 3 0))) (vector-set! v 1 'x) v)`, want: `#(0 x 0)`},
+		{src: `(list (vector->list #(1 2 3)) (vector->list #(1 2 3) 1) (vector->list #(1 2 3) 1 2))`, want: `((1 2 3) (2 3) (2))`},
+		{src: `(list->vector (list 1 2))`, want: `#(1 2)`},
+		{src: `(let ((v (vector 1 2 3 4))) (vector-fill! v 0 1 3) v)`, want: `#(1 0 0 4)`},
+		{src: `(let ((v (vector 1 2))) (vector-fill! v 'z) v)`, want: `#(z z)`},
+		{src: `(vector-map + #(1 2 3) #(10 20))`, want: `#(11 22)`},
+		{src: `(vector-map (lambda (x) (* x x)) #(1 2 3))`, want: `#(1 4 9)`},
+		{src: `(let ((sum 0)) (vector-for-each (lambda (x) (set! sum (+ sum x))) #(1 2 3)) sum)`, want: `6`},
+		{src: `(list (equal? #(1 (2)) (vector 1 (list 2))) (equal? #(1) #(2)) (eqv? #() #()) (let ((v #(1))) (eq? v v)))`, want: `(#t #f #f #t)`},
+		{src: `(let ((v (vector 1 2))) (vector-set! v 1 v) v)`, want: `#0=#(1 #0#)`},
+		{src: `(let ((v (vector 1)) (w (vector 1))) (vector-set! v 0 v) (vector-set! w 0 w) (equal? v w))`, want: `#t`},
+		{
+			src: `
+				(define-syntax first
+				  (syntax-rules ()
+				    ((_ #(a b ...)) 'a)
+				    ((_ x) 'not-a-vector)))
+				(list (first #(1 2 3)) (first (1 2 3)))`,
+			want: `(1 not-a-vector)`,
+		},
+		{src: `(vector-ref #(1 2) 2)`, wantErr: errIndexOutOfRange},
+		{src: `(vector-ref #() 0)`, wantErr: errIndexOutOfRange},
+		{src: `(vector-set! (vector 1) -1 0)`, wantErr: errIndexOutOfRange},
+		{src: `(vector->list #(1 2) 2 1)`, wantErr: errIndexOutOfRange},
+		{src: `(vector-ref '(1 2) 0)`, wantErr: errInvalidArgumentType},
+		{src: `(vector-ref #(1 2) 'a)`, wantErr: errInvalidArgumentType},
+		{src: `(make-vector -1)`, wantErr: errIndexOutOfRange},
+		{src: `(make-vector 100000000 0)`, wantErr: errIndexOutOfRange},
+		{src: `(list->vector 1)`, wantErr: errInvalidArgumentType},
+		{src: `(vector-map 1 #(1))`, wantErr: errInvalidArgumentType},
+		{src: `(vector-map car)`, wantErr: errWrongNumberOfArguments},
+		{src: `#(1 . 2)`, wantErr: errInvalidCompoundExpression},
+	}
+
+	in := NewInterpreter()
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := in.Eval(c.src)
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+			continue
+		}
+
+		if gotErr == nil && Repr(got) != c.want {
+			t.Errorf("value:\ngot:  %v\nwant: %v", Repr(got), c.want)
+		}
+	}
+}
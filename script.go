@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"scgeme/scheme"
+)
+
+// stripShebang blanks out a leading "#!" line so that scripts can be made
+// directly executable. The newline is kept so that line numbers in the rest of
+// the source are unaffected.
+func stripShebang(src string) string {
+	if !strings.HasPrefix(src, "#!") {
+		return src
+	}
+
+	if i := strings.IndexByte(src, '\n'); i >= 0 {
+		return src[i:]
+	}
+
+	return ""
+}
+
+// runSource evaluates every expression in src with interp and returns the
+// value of the last one. Positions in errors are reported against name.
+func runSource(name, src string, interp *scheme.Interpreter) (scheme.Value, error) {
+	return interp.EvalSource(name, stripShebang(src))
+}
+
+// runFile evaluates the source file at path with interp. A path of "-" reads
+// from stdin.
+func runFile(path string, interp *scheme.Interpreter) (scheme.Value, error) {
+	if path == "-" {
+		return runReader("stdin", os.Stdin, interp)
+	}
+
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return runSource(path, string(src), interp)
+}
+
+// runReader evaluates everything readable from r with interp.
+func runReader(name string, r io.Reader, interp *scheme.Interpreter) (scheme.Value, error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return runSource(name, string(src), interp)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"scgeme/errs"
+	"scgeme/scheme"
+)
+
+func TestStripShebang(t *testing.T) {
+	cases := []struct {
+		src  string
+		want string
+	}{
+		{
+			src:  ``,
+			want: ``,
+		},
+		{
+			src:  `(+ 1 2)`,
+			want: `(+ 1 2)`,
+		},
+		{
+			src:  "#!/usr/bin/env scgeme\n(+ 1 2)\n",
+			want: "\n(+ 1 2)\n",
+		},
+		{
+			src:  "#!/usr/bin/env scgeme",
+			want: "",
+		},
+		{
+			src:  "(+ 1 2)\n#!foo\n",
+			want: "(+ 1 2)\n#!foo\n",
+		},
+	}
+
+	for _, c := range cases {
+		if got := stripShebang(c.src); got != c.want {
+			t.Errorf("%q:\ngot:  %q\nwant: %q", c.src, got, c.want)
+		}
+	}
+}
+
+func TestRunSource(t *testing.T) {
+	cases := []struct {
+		src     string
+		want    scheme.Value
+		wantErr error
+	}{
+		{
+			src:  ``,
+			want: scheme.Null(),
+		},
+		{
+			src:  "#!/usr/bin/env scgeme\n(define (sq x) (* x x))\n(sq 3)\n",
+			want: scheme.Number(9),
+		},
+		{
+			src:     `(car`,
+			wantErr: scheme.ErrUnclosedExpression,
+		},
+	}
+
+	for i, c := range cases {
+		t.Logf("Case %d: %v", i, c.src)
+
+		got, gotErr := runSource("test", c.src, scheme.NewInterpreter())
+
+		if errs.Root(gotErr) != c.wantErr {
+			t.Errorf("error:\ngot:  %v\nwant: %v", gotErr, c.wantErr)
+		}
+
+		if gotErr != nil && !strings.HasPrefix(gotErr.Error(), "test:1:1: ") {
+			t.Errorf("error should be prefixed with source position: %v", gotErr)
+		}
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("value:\ngot:  %v\nwant: %v", got, c.want)
+		}
+	}
+
+	if _, err := runSource("test", `(car 1)`, scheme.NewInterpreter()); err == nil {
+		t.Error("expected evaluation error")
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scgeme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	lib := filepath.Join(dir, "lib.scm")
+	if err := ioutil.WriteFile(lib, []byte("(define (double x) (+ x x))\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	main := filepath.Join(dir, "main.scm")
+	if err := ioutil.WriteFile(main, []byte("#!/usr/bin/env scgeme\n(double 21)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	interp := scheme.NewInterpreter()
+
+	if _, err := runFile(lib, interp); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := runFile(main, interp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, scheme.Number(42)) {
+		t.Errorf("value:\ngot:  %v\nwant: %v", got, scheme.Number(42))
+	}
+
+	if _, err := runFile(filepath.Join(dir, "missing.scm"), interp); err == nil {
+		t.Error("expected error for missing file")
+	}
+}